@@ -0,0 +1,246 @@
+package mixpanel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is returned or reported when the asynchronous queue drops an item because it is full.
+var ErrQueueFull = errors.New("Mixpanel queue is full")
+
+// ErrClosed is returned when tracking through a BufferedConsumer after Close has been called.
+var ErrClosed = errors.New("Mixpanel client is closed")
+
+// OverflowPolicy decides what happens when the asynchronous queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the caller wait until there is room in the queue or its context is done.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards the new item and returns ErrQueueFull to the caller.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest queued item to make room and reports ErrQueueFull to OnError.
+	OverflowDropOldest
+)
+
+// AsyncOptions configures a BufferedConsumer and the asynchronous mode enabled by WithAsync.
+// Zero values are replaced by the defaults noted on each field.
+type AsyncOptions struct {
+	// QueueSize is the maximum number of queued items, 1000 by default.
+	QueueSize int
+	// BatchSize is the number of queued items which triggers a flush, 50 by default and at most 50.
+	BatchSize int
+	// FlushInterval is the longest time an item waits in the queue, 5 seconds by default.
+	FlushInterval time.Duration
+	// Overflow decides what happens when the queue is full, OverflowBlock by default.
+	Overflow OverflowPolicy
+	// OnError receives errors from background flushes and dropped items, they are discarded when it is nil.
+	OnError func(error)
+}
+
+// WithAsync makes track and profile calls return as soon as their payload is queued.
+// A background goroutine sends the queue in batches, call Flush or Close to drain it.
+// It is a shortcut for WithConsumer with a BufferedConsumer around the client's HTTPConsumer.
+func WithAsync(options AsyncOptions) Option {
+	return func(m *MixPanel) {
+		m.consumer = NewBufferedConsumer(m.HTTPConsumer(), options)
+	}
+}
+
+// Flush sends everything queued by the client's consumer and waits until it has been delivered.
+// It does nothing when the consumer does not buffer.
+func (m *MixPanel) Flush() error {
+	if flusher, ok := m.consumer.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// Close flushes and stops the client's consumer when it supports it, for example a BufferedConsumer.
+// Track and profile calls made after closing a BufferedConsumer return ErrClosed.
+func (m *MixPanel) Close() error {
+	if closer, ok := m.consumer.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// queuedItem is a payload waiting to be sent to an endpoint.
+type queuedItem struct {
+	endpoint Endpoint
+	payload  map[string]interface{}
+}
+
+// BufferedConsumer queues payloads in memory and passes them in batches to the next consumer from a background goroutine.
+type BufferedConsumer struct {
+	options AsyncOptions
+	next    Consumer
+	items   chan queuedItem
+	flushes chan chan error
+	done    chan struct{}
+	mutex   sync.RWMutex
+	closed  bool
+}
+
+// NewBufferedConsumer creates a BufferedConsumer which delivers through next and starts its background goroutine.
+// Call Close to drain the queue and stop the goroutine.
+func NewBufferedConsumer(next Consumer, options AsyncOptions) *BufferedConsumer {
+	if options.QueueSize <= 0 {
+		options.QueueSize = 1000
+	}
+	if options.BatchSize <= 0 || options.BatchSize > maxBatchSize {
+		options.BatchSize = maxBatchSize
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = 5 * time.Second
+	}
+	var q = &BufferedConsumer{
+		options: options,
+		next:    next,
+		items:   make(chan queuedItem, options.QueueSize),
+		flushes: make(chan chan error),
+		done:    make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// Consume queues the payloads following the overflow policy.
+func (q *BufferedConsumer) Consume(ctx context.Context, endpoint Endpoint, payloads []map[string]interface{}) error {
+	for _, payload := range payloads {
+		if err := q.enqueue(ctx, endpoint, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueue adds the payload to the queue following the overflow policy.
+func (q *BufferedConsumer) enqueue(ctx context.Context, endpoint Endpoint, payload map[string]interface{}) error {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+	if q.closed {
+		return ErrClosed
+	}
+	var item = queuedItem{endpoint: endpoint, payload: payload}
+	switch q.options.Overflow {
+	case OverflowDropNewest:
+		select {
+		case q.items <- item:
+			return nil
+		default:
+			return ErrQueueFull
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case q.items <- item:
+				return nil
+			default:
+			}
+			select {
+			case <-q.items:
+				q.report(ErrQueueFull)
+			default:
+			}
+		}
+	default:
+		select {
+		case q.items <- item:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Flush asks the background goroutine to send everything queued and waits for the result.
+func (q *BufferedConsumer) Flush() error {
+	var result = make(chan error)
+	select {
+	case q.flushes <- result:
+		return <-result
+	case <-q.done:
+		return nil
+	}
+}
+
+// Close stops accepting payloads, drains the queue and stops the background goroutine.
+func (q *BufferedConsumer) Close() error {
+	q.mutex.Lock()
+	if q.closed {
+		q.mutex.Unlock()
+		return nil
+	}
+	q.closed = true
+	q.mutex.Unlock()
+	var err = q.Flush()
+	close(q.done)
+	return err
+}
+
+// run collects queued items and sends them when the batch is full, the interval passes or a flush is requested.
+func (q *BufferedConsumer) run() {
+	var ticker = time.NewTicker(q.options.FlushInterval)
+	defer ticker.Stop()
+	var pending []queuedItem
+	for {
+		select {
+		case item := <-q.items:
+			pending = append(pending, item)
+			if len(pending) >= q.options.BatchSize {
+				q.report(q.send(pending))
+				pending = nil
+			}
+		case <-ticker.C:
+			q.report(q.send(pending))
+			pending = nil
+		case result := <-q.flushes:
+			pending = q.drain(pending)
+			result <- q.send(pending)
+			pending = nil
+		case <-q.done:
+			return
+		}
+	}
+}
+
+// drain moves every item currently in the channel to pending.
+func (q *BufferedConsumer) drain(pending []queuedItem) []queuedItem {
+	for {
+		select {
+		case item := <-q.items:
+			pending = append(pending, item)
+		default:
+			return pending
+		}
+	}
+}
+
+// send passes the items to the next consumer grouped by endpoint.
+func (q *BufferedConsumer) send(items []queuedItem) error {
+	var endpoints []Endpoint
+	var grouped = make(map[Endpoint][]map[string]interface{})
+	for _, item := range items {
+		if _, ok := grouped[item.endpoint]; !ok {
+			endpoints = append(endpoints, item.endpoint)
+		}
+		grouped[item.endpoint] = append(grouped[item.endpoint], item.payload)
+	}
+	var errs []error
+	for _, endpoint := range endpoints {
+		if err := q.next.Consume(context.Background(), endpoint, grouped[endpoint]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// report passes err to OnError.
+func (q *BufferedConsumer) report(err error) {
+	if err != nil && q.options.OnError != nil {
+		q.options.OnError(err)
+	}
+}
@@ -0,0 +1,181 @@
+package mixpanel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// requestCount returns the number of requests the server has received.
+func (s *testServer) requestCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.requests)
+}
+
+// waitFor polls condition until it is true or a second has passed.
+func waitFor(t *testing.T, condition func() bool) {
+	var deadline = time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// newBlockingServer starts a server which holds every request until release is closed.
+func newBlockingServer(t *testing.T) (server *httptest.Server, received chan struct{}, release chan struct{}) {
+	received = make(chan struct{}, 100)
+	release = make(chan struct{})
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+		fmt.Fprint(w, "1")
+	}))
+	t.Cleanup(server.Close)
+	return
+}
+
+func TestAsyncBatchSize(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithAsync(AsyncOptions{BatchSize: 10, FlushInterval: time.Hour}))
+	defer mixpanel.Close()
+	for i := 0; i < 15; i++ {
+		if err := mixpanel.TrackEventOnly("Test Async"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, func() bool { return server.requestCount() == 1 })
+	if err := mixpanel.ProfileSet("User 0001", map[string]interface{}{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mixpanel.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if server.requestCount() != 3 {
+		t.Fatal("Expected 3 requests, got", server.requestCount())
+	}
+	if len(server.payloads[0].([]interface{})) != 10 || len(server.payloads[1].([]interface{})) != 5 {
+		t.Error("Unexpected batch sizes", server.payloads)
+	}
+	if server.requests[2].URL.Path != engagePath {
+		t.Error("Unexpected path", server.requests[2].URL.Path)
+	}
+}
+
+func TestAsyncFlushInterval(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithAsync(AsyncOptions{FlushInterval: 10 * time.Millisecond}))
+	defer mixpanel.Close()
+	if err := mixpanel.TrackEventOnly("Test Async"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return server.requestCount() == 1 })
+}
+
+func TestAsyncClose(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithAsync(AsyncOptions{FlushInterval: time.Hour}))
+	if err := mixpanel.TrackEvents(testEvents(3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := mixpanel.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if server.requestCount() != 1 {
+		t.Error("Expected the queue to be drained on close")
+	}
+	if err := mixpanel.TrackEventOnly("Test Async"); err != ErrClosed {
+		t.Error("Expected ErrClosed, got", err)
+	}
+	if err := mixpanel.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAsyncFlushError(t *testing.T) {
+	var server = newTestServer(t, "0")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithAsync(AsyncOptions{FlushInterval: time.Hour}))
+	defer mixpanel.Close()
+	if err := mixpanel.TrackEvents(testEvents(2)); err != nil {
+		t.Fatal(err)
+	}
+	var batchError *BatchError
+	if err := mixpanel.Flush(); !errors.As(err, &batchError) {
+		t.Error("Expected a BatchError, got", err)
+	}
+}
+
+func TestAsyncOverflowDropNewest(t *testing.T) {
+	var server, received, release = newBlockingServer(t)
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithAsync(AsyncOptions{QueueSize: 1, BatchSize: 1, Overflow: OverflowDropNewest}))
+	defer mixpanel.Close()
+	defer close(release)
+	if err := mixpanel.TrackEventOnly("First"); err != nil {
+		t.Fatal(err)
+	}
+	<-received
+	if err := mixpanel.TrackEventOnly("Second"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mixpanel.TrackEventOnly("Third"); err != ErrQueueFull {
+		t.Error("Expected ErrQueueFull, got", err)
+	}
+}
+
+func TestAsyncOverflowDropOldest(t *testing.T) {
+	var server, received, release = newBlockingServer(t)
+	var mutex sync.Mutex
+	var reported []error
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithAsync(AsyncOptions{
+		QueueSize: 1,
+		BatchSize: 1,
+		Overflow:  OverflowDropOldest,
+		OnError: func(err error) {
+			mutex.Lock()
+			reported = append(reported, err)
+			mutex.Unlock()
+		},
+	}))
+	defer mixpanel.Close()
+	defer close(release)
+	if err := mixpanel.TrackEventOnly("First"); err != nil {
+		t.Fatal(err)
+	}
+	<-received
+	for _, event := range []string{"Second", "Third"} {
+		if err := mixpanel.TrackEventOnly(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(reported) != 1 || reported[0] != ErrQueueFull {
+		t.Error("Expected one dropped item, got", reported)
+	}
+}
+
+func TestAsyncOverflowBlock(t *testing.T) {
+	var server, received, release = newBlockingServer(t)
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithAsync(AsyncOptions{QueueSize: 1, BatchSize: 1}))
+	defer mixpanel.Close()
+	defer close(release)
+	if err := mixpanel.TrackEventOnly("First"); err != nil {
+		t.Fatal(err)
+	}
+	<-received
+	if err := mixpanel.TrackEventOnly("Second"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := mixpanel.TrackEventOnlyContext(ctx, "Third"); err != context.DeadlineExceeded {
+		t.Error("Expected the call to block until the deadline, got", err)
+	}
+}
@@ -0,0 +1,132 @@
+package mixpanel
+
+import (
+	"context"
+	"fmt"
+)
+
+// maxBatchSize is the largest number of records mixpanel accepts in a single /track or /engage request.
+const maxBatchSize = 50
+
+// Profile update operators accepted by the /engage endpoint.
+const (
+	OperatorSet     string = "$set"
+	OperatorSetOnce string = "$set_once"
+	OperatorAdd     string = "$add"
+	OperatorAppend  string = "$append"
+	OperatorUnion   string = "$union"
+	OperatorRemove  string = "$remove"
+	OperatorUnset   string = "$unset"
+	OperatorDelete  string = "$delete"
+)
+
+// ProfileOperation is a single profile update for the batch engage API.
+// Value has the same form as the argument of the matching Profile method, for example a map for OperatorSet
+// or a list of property names for OperatorUnset.
+type ProfileOperation struct {
+	DistinctID string
+	Operator   string
+	Value      interface{}
+}
+
+// ChunkError records the failure of one request of a batch.
+// Start and End index the items of that request in the slice passed to the batch call, End is exclusive.
+type ChunkError struct {
+	Chunk int
+	Start int
+	End   int
+	Err   error
+}
+
+// Error returns the reason the chunk failed along with its position in the batch.
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("chunk %d (items %d to %d) failed: %v", e.Chunk, e.Start, e.End-1, e.Err)
+}
+
+// Unwrap returns the underlying error of the chunk.
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError is returned by the batch calls when one or more chunks failed.
+// Chunks which are not listed were delivered successfully.
+type BatchError struct {
+	Total  int
+	Chunks []*ChunkError
+}
+
+// Error summarises the failed chunks.
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of %d chunks failed, first error: %v", len(e.Chunks), e.Total, e.Chunks[0])
+}
+
+// Unwrap returns the errors of the failed chunks so they can be inspected with errors.Is and errors.As.
+func (e *BatchError) Unwrap() []error {
+	var errs = make([]error, len(e.Chunks))
+	for i, chunk := range e.Chunks {
+		errs[i] = chunk
+	}
+	return errs
+}
+
+// batchKey marks the context of the batch calls, so the HTTP consumer sends even a single payload
+// in the batch form and reports failures with a *BatchError.
+type batchKey struct{}
+
+// TrackEvents tracks the events using the batch form of the /track endpoint.
+// Events are sent in chunks of 50, a *BatchError describes the chunks which failed.
+func (m *MixPanel) TrackEvents(events []Event) error {
+	return m.TrackEventsContext(context.Background(), events)
+}
+
+// TrackEventsContext is like TrackEvents but uses ctx for the outgoing requests.
+func (m *MixPanel) TrackEventsContext(ctx context.Context, events []Event) error {
+	var packets = make([]map[string]interface{}, len(events))
+	for i := range events {
+		packets[i] = m.eventPacket(&events[i])
+	}
+	return m.consume(context.WithValue(ctx, batchKey{}, true), EndpointTrack, packets)
+}
+
+// deliverBatch sends the payloads to the endpoint as JSON arrays of up to maxBatchSize items.
+func (m *MixPanel) deliverBatch(ctx context.Context, endpoint Endpoint, payloads []map[string]interface{}) error {
+	var endpointURL = m.endpoint(endpoint.path())
+	var batchError = &BatchError{Total: (len(payloads) + maxBatchSize - 1) / maxBatchSize}
+	for chunk, start := 0, 0; start < len(payloads); chunk, start = chunk+1, start+maxBatchSize {
+		var end = start + maxBatchSize
+		if end > len(payloads) {
+			end = len(payloads)
+		}
+		if err := m.handleHTTPCall(ctx, payloads[start:end], endpointURL); err != nil {
+			batchError.Chunks = append(batchError.Chunks, &ChunkError{Chunk: chunk, Start: start, End: end, Err: err})
+		}
+	}
+	if len(batchError.Chunks) > 0 {
+		return batchError
+	}
+	return nil
+}
+
+// ProfileBatch sends the profile operations using the batch form of the /engage endpoint.
+// Operations may be for different users and use different operators.
+// They are sent in chunks of 50, a *BatchError describes the chunks which failed.
+func (m *MixPanel) ProfileBatch(operations []ProfileOperation) error {
+	return m.ProfileBatchContext(context.Background(), operations)
+}
+
+// ProfileBatchContext is like ProfileBatch but uses ctx for the outgoing requests.
+func (m *MixPanel) ProfileBatchContext(ctx context.Context, operations []ProfileOperation) error {
+	var records = make([]map[string]interface{}, len(operations))
+	for i, operation := range operations {
+		var value = operation.Value
+		if operation.Operator == OperatorDelete && value == nil {
+			value = ""
+		}
+		records[i] = map[string]interface{}{
+			"$token":           m.Token,
+			"$distinct_id":     operation.DistinctID,
+			operation.Operator: value,
+		}
+	}
+	return m.consume(context.WithValue(ctx, batchKey{}, true), EndpointEngage, records)
+}
@@ -0,0 +1,133 @@
+package mixpanel
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func testEvents(count int) []Event {
+	var events = make([]Event, count)
+	for i := range events {
+		events[i] = Event{
+			Name:       "Test TrackEvents",
+			Properties: map[string]interface{}{"distinct_id": "User 0001", "index": i},
+		}
+	}
+	return events
+}
+
+func TestTrackEventsChunks(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	if err := mixpanel.TrackEvents(testEvents(120)); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.payloads) != 3 {
+		t.Fatal("Expected 3 requests, got", len(server.payloads))
+	}
+	var sizes = []int{50, 50, 20}
+	for i, payload := range server.payloads {
+		var records, ok = payload.([]interface{})
+		if !ok || len(records) != sizes[i] {
+			t.Error("Unexpected chunk", i, payload)
+			continue
+		}
+		var properties = records[0].(map[string]interface{})["properties"].(map[string]interface{})
+		if properties["token"] != "token" || properties["index"] != float64(i*50) {
+			t.Error("Unexpected properties", properties)
+		}
+	}
+}
+
+func TestTrackEventsEmpty(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	if err := mixpanel.TrackEvents(nil); err != nil {
+		t.Error(err)
+	}
+	if len(server.payloads) != 0 {
+		t.Error("Expected no requests, got", len(server.payloads))
+	}
+}
+
+func TestTrackEventsChunkFailure(t *testing.T) {
+	var mutex sync.Mutex
+	var calls = 0
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		calls++
+		var call = calls
+		mutex.Unlock()
+		fmt.Fprint(w, strconv.Itoa(call%2))
+	}))
+	defer server.Close()
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	var err = mixpanel.TrackEvents(testEvents(120))
+	var batchError *BatchError
+	if !errors.As(err, &batchError) {
+		t.Fatal("Expected a BatchError, got", err)
+	}
+	if batchError.Total != 3 || len(batchError.Chunks) != 1 {
+		t.Fatal("Unexpected batch error", batchError)
+	}
+	var chunk = batchError.Chunks[0]
+	if chunk.Chunk != 1 || chunk.Start != 50 || chunk.End != 100 {
+		t.Error("Unexpected failed chunk", chunk)
+	}
+}
+
+func TestProfileBatch(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	var operations = []ProfileOperation{
+		{DistinctID: "User 0001", Operator: OperatorSet, Value: map[string]interface{}{"$name": "One"}},
+		{DistinctID: "User 0002", Operator: OperatorAdd, Value: map[string]int64{"logins": 1}},
+		{DistinctID: "User 0003", Operator: OperatorUnset, Value: []string{"movies"}},
+		{DistinctID: "User 0004", Operator: OperatorDelete},
+	}
+	for i := 0; i < 60; i++ {
+		operations = append(operations, ProfileOperation{DistinctID: "User 0005", Operator: OperatorUnion, Value: map[string]interface{}{"tags": []string{"a"}}})
+	}
+	if err := mixpanel.ProfileBatch(operations); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.payloads) != 2 {
+		t.Fatal("Expected 2 requests, got", len(server.payloads))
+	}
+	if server.requests[0].URL.Path != engagePath {
+		t.Error("Unexpected path", server.requests[0].URL.Path)
+	}
+	var records = server.payloads[0].([]interface{})
+	var set = records[0].(map[string]interface{})
+	if set["$distinct_id"] != "User 0001" || set["$token"] != "token" || set["$set"] == nil {
+		t.Error("Unexpected $set record", set)
+	}
+	var deleted = records[3].(map[string]interface{})
+	if value, ok := deleted["$delete"]; !ok || value != "" {
+		t.Error("Unexpected $delete record", deleted)
+	}
+	if len(server.payloads[1].([]interface{})) != 14 {
+		t.Error("Unexpected size of second chunk")
+	}
+}
+
+func TestBatchOfOne(t *testing.T) {
+	var server = newTestServer(t, "0")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	var batchError *BatchError
+	if err := mixpanel.TrackEvents(testEvents(1)); !errors.As(err, &batchError) {
+		t.Error("Expected a *BatchError for a batch of one event, got", err)
+	}
+	if _, ok := server.payloads[0].([]interface{}); !ok {
+		t.Error("Expected a batch of one event to be sent as an array, got", server.payloads[0])
+	}
+	var operations = []ProfileOperation{{DistinctID: "User 0001", Operator: OperatorSet, Value: map[string]interface{}{"a": 1}}}
+	if err := mixpanel.ProfileBatch(operations); !errors.As(err, &batchError) {
+		t.Error("Expected a *BatchError for a batch of one operation, got", err)
+	}
+}
@@ -0,0 +1,78 @@
+package mixpanel
+
+import (
+	"net/url"
+	"strings"
+)
+
+// direct is the $referrer and $referring_domain value used when there is no referrer, as in the mixpanel JavaScript library.
+const direct = "$direct"
+
+// campaignParameters are the utm parameters and ad click ids copied from the landing page URL.
+var campaignParameters = []string{
+	"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content",
+	"gclid", "gbraid", "wbraid", "dclid", "fbclid", "msclkid", "ttclid", "twclid", "li_fat_id", "sccid", "ko_click_id",
+}
+
+// Campaign returns an Enricher which sets the utm and click id properties found in the landing page URL,
+// and the $referrer and $referring_domain properties, see CampaignProperties.
+func Campaign(landingURL string, referrer string) Enricher {
+	return func(e *Event) {
+		e.Props(CampaignProperties(landingURL, referrer))
+	}
+}
+
+// CampaignProperties returns the utm_source, utm_medium, utm_campaign, utm_term, utm_content and ad click id
+// (gclid, fbclid, msclkid etc.) query parameters of the landing page URL, together with $referrer and
+// $referring_domain. Both referrer properties are "$direct" when referrer is empty.
+// Parameters which are missing or empty are left out, as is everything from a URL which cannot be parsed.
+func CampaignProperties(landingURL string, referrer string) map[string]interface{} {
+	var properties = make(map[string]interface{})
+	for key, value := range campaignQuery(landingURL) {
+		properties[key] = value
+	}
+	properties["$referrer"], properties["$referring_domain"] = referrerProperties(referrer)
+	return properties
+}
+
+// FirstTouch sets the campaign properties of the landing page URL and referrer on the profile
+// only if they have no value yet, so the profile keeps the campaign which first brought the user.
+// The utm and click id properties are prefixed with initial_, such as initial_utm_source,
+// and the referrer is kept in $initial_referrer and $initial_referring_domain.
+func (u *ProfileUpdate) FirstTouch(landingURL string, referrer string) *ProfileUpdate {
+	for key, value := range campaignQuery(landingURL) {
+		u.SetOnce("initial_"+key, value)
+	}
+	var referrerURL, referringDomain = referrerProperties(referrer)
+	u.SetOnce("$initial_referrer", referrerURL)
+	return u.SetOnce("$initial_referring_domain", referringDomain)
+}
+
+// campaignQuery returns the non empty campaign parameters of the URL.
+func campaignQuery(landingURL string) map[string]string {
+	var parameters = make(map[string]string)
+	parsed, err := url.Parse(landingURL)
+	if err != nil {
+		return parameters
+	}
+	var query = parsed.Query()
+	for _, key := range campaignParameters {
+		if value := strings.TrimSpace(query.Get(key)); value != "" {
+			parameters[key] = value
+		}
+	}
+	return parameters
+}
+
+// referrerProperties returns the $referrer and $referring_domain values for the referrer.
+func referrerProperties(referrer string) (string, string) {
+	referrer = strings.TrimSpace(referrer)
+	if referrer == "" {
+		return direct, direct
+	}
+	parsed, err := url.Parse(referrer)
+	if err != nil || parsed.Hostname() == "" {
+		return referrer, direct
+	}
+	return referrer, parsed.Hostname()
+}
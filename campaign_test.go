@@ -0,0 +1,61 @@
+package mixpanel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCampaignProperties(t *testing.T) {
+	var properties = CampaignProperties(
+		"https://example.com/signup?utm_source=newsletter&utm_medium=email&utm_campaign=launch&utm_term=&gclid=abc123&page=2",
+		"https://www.google.com/search?q=example",
+	)
+	var expected = map[string]interface{}{
+		"utm_source":        "newsletter",
+		"utm_medium":        "email",
+		"utm_campaign":      "launch",
+		"gclid":             "abc123",
+		"$referrer":         "https://www.google.com/search?q=example",
+		"$referring_domain": "www.google.com",
+	}
+	if !reflect.DeepEqual(properties, expected) {
+		t.Error("Expected", expected, "got", properties)
+	}
+}
+
+func TestCampaignPropertiesDirect(t *testing.T) {
+	var properties = CampaignProperties("%zz", "")
+	var expected = map[string]interface{}{"$referrer": "$direct", "$referring_domain": "$direct"}
+	if !reflect.DeepEqual(properties, expected) {
+		t.Error("Expected", expected, "got", properties)
+	}
+}
+
+func TestEventEnrichCampaign(t *testing.T) {
+	var event = NewEvent("Signup").Enrich(Campaign("https://example.com/?utm_source=ads&fbclid=xyz", "https://facebook.com/"))
+	if event.Properties["utm_source"] != "ads" || event.Properties["fbclid"] != "xyz" || event.Properties["$referring_domain"] != "facebook.com" {
+		t.Error("Expected the campaign properties to be set, got", event.Properties)
+	}
+}
+
+func TestProfileUpdateFirstTouch(t *testing.T) {
+	operations, err := NewProfileUpdate("User 0001").
+		FirstTouch("https://example.com/?utm_source=ads&utm_campaign=spring", "").
+		Operations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []ProfileOperation{{
+		DistinctID: "User 0001",
+		Operator:   OperatorSetOnce,
+		Value: map[string]interface{}{
+			"initial_utm_source":        "ads",
+			"initial_utm_campaign":      "spring",
+			"$initial_referrer":         "$direct",
+			"$initial_referring_domain": "$direct",
+		},
+	}}
+	if !reflect.DeepEqual(operations, expected) {
+		t.Error("Expected", expected, "got", operations)
+	}
+}
@@ -0,0 +1,111 @@
+package mixpanel
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Endpoint identifies the mixpanel API a payload is meant for.
+type Endpoint int
+
+const (
+	// EndpointTrack receives events, https://api.mixpanel.com/track/.
+	EndpointTrack Endpoint = iota
+	// EndpointEngage receives user profile updates, https://api.mixpanel.com/engage/.
+	EndpointEngage
+	// EndpointGroups receives group profile updates, https://api.mixpanel.com/groups/.
+	EndpointGroups
+)
+
+// path returns the API path of the endpoint.
+func (e Endpoint) path() string {
+	switch e {
+	case EndpointEngage:
+		return engagePath
+	case EndpointGroups:
+		return groupsPath
+	default:
+		return trackPath
+	}
+}
+
+// String returns the name of the endpoint.
+func (e Endpoint) String() string {
+	switch e {
+	case EndpointEngage:
+		return "engage"
+	case EndpointGroups:
+		return "groups"
+	default:
+		return "track"
+	}
+}
+
+// Consumer delivers payloads which have already been built by MixPanel.
+// Each payload is a complete event or engage record including the token.
+// Implementations may send them anywhere, for example a message queue or a sidecar process.
+type Consumer interface {
+	Consume(ctx context.Context, endpoint Endpoint, payloads []map[string]interface{}) error
+}
+
+// WithConsumer hands every payload to consumer instead of sending it to mixpanel directly.
+func WithConsumer(consumer Consumer) Option {
+	return func(m *MixPanel) {
+		m.consumer = consumer
+	}
+}
+
+// HTTPConsumer returns a Consumer which sends payloads straight to mixpanel using the client's
+// region, base URL and HTTP client. It is the default consumer and can be wrapped by other consumers.
+func (m *MixPanel) HTTPConsumer() Consumer {
+	return httpConsumer{m: m}
+}
+
+// httpConsumer delivers payloads over HTTP with the settings of m.
+type httpConsumer struct {
+	m *MixPanel
+}
+
+// Consume sends a single payload as a JSON object and several as JSON arrays of up to 50 items.
+// Payloads of the batch calls always use arrays so their failures are reported with a *BatchError.
+func (c httpConsumer) Consume(ctx context.Context, endpoint Endpoint, payloads []map[string]interface{}) error {
+	if len(payloads) == 1 && ctx.Value(batchKey{}) == nil {
+		return c.m.handleHTTPCall(ctx, payloads[0], c.m.endpoint(endpoint.path()))
+	}
+	return c.m.deliverBatch(ctx, endpoint, payloads)
+}
+
+// NoopConsumer discards every payload, it is useful in tests and for disabling tracking.
+type NoopConsumer struct{}
+
+// Consume discards the payloads.
+func (NoopConsumer) Consume(ctx context.Context, endpoint Endpoint, payloads []map[string]interface{}) error {
+	return nil
+}
+
+// LoggingConsumer writes every payload to Logger at debug level as JSON and then passes it to Next when Next is set.
+// Logger may be a *slog.Logger, or StdLogger when nil. Payloads contain whatever properties were tracked,
+// so take care where the log ends up.
+type LoggingConsumer struct {
+	Logger Logger
+	Next   Consumer
+}
+
+// Consume logs the payloads and forwards them to Next.
+func (c LoggingConsumer) Consume(ctx context.Context, endpoint Endpoint, payloads []map[string]interface{}) error {
+	var logger = c.Logger
+	if logger == nil {
+		logger = StdLogger{}
+	}
+	for _, payload := range payloads {
+		jsonBytes, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		logger.Debug("Mixpanel payload", "endpoint", endpoint, "payload", string(jsonBytes))
+	}
+	if c.Next == nil {
+		return nil
+	}
+	return c.Next.Consume(ctx, endpoint, payloads)
+}
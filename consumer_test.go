@@ -0,0 +1,101 @@
+package mixpanel
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingConsumer keeps every payload it receives.
+type recordingConsumer struct {
+	mutex     sync.Mutex
+	endpoints []Endpoint
+	payloads  []map[string]interface{}
+}
+
+func (c *recordingConsumer) Consume(ctx context.Context, endpoint Endpoint, payloads []map[string]interface{}) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, payload := range payloads {
+		c.endpoints = append(c.endpoints, endpoint)
+		c.payloads = append(c.payloads, payload)
+	}
+	return nil
+}
+
+func TestWithConsumer(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	if err := mixpanel.TrackEventForUser("Test Consumer", "User 0001"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mixpanel.ProfileDelete("User 0001"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mixpanel.TrackEvents(testEvents(3)); err != nil {
+		t.Fatal(err)
+	}
+	if len(consumer.payloads) != 5 {
+		t.Fatal("Expected 5 payloads, got", len(consumer.payloads))
+	}
+	if consumer.endpoints[0] != EndpointTrack || consumer.payloads[0]["event"] != "Test Consumer" {
+		t.Error("Unexpected event payload", consumer.endpoints[0], consumer.payloads[0])
+	}
+	if consumer.endpoints[1] != EndpointEngage || consumer.payloads[1]["$distinct_id"] != "User 0001" {
+		t.Error("Unexpected engage payload", consumer.endpoints[1], consumer.payloads[1])
+	}
+}
+
+func TestNoopConsumer(t *testing.T) {
+	var mixpanel = NewMixPanel("token", WithBaseURL("http://invalid.invalid"), WithConsumer(NoopConsumer{}))
+	if err := mixpanel.TrackEventOnly("Test NoopConsumer"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoggingConsumer(t *testing.T) {
+	var buffer bytes.Buffer
+	var next = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(LoggingConsumer{Logger: StdLogger{Logger: log.New(&buffer, "", 0)}, Next: next}))
+	if err := mixpanel.ProfileSet("User 0001", map[string]interface{}{"$name": "One"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buffer.String(), "DEBUG Mixpanel payload endpoint=engage payload={") || !strings.Contains(buffer.String(), `"$name":"One"`) {
+		t.Error("Unexpected log output", buffer.String())
+	}
+	if len(next.payloads) != 1 {
+		t.Error("Expected the payload to be forwarded")
+	}
+}
+
+func TestLoggingConsumerLogger(t *testing.T) {
+	var logger = &recordingLogger{}
+	var mixpanel = NewMixPanel("token", WithConsumer(LoggingConsumer{Logger: logger}))
+	if err := mixpanel.Track(NewEvent("Signup").DistinctID("User 0001")); err != nil {
+		t.Fatal(err)
+	}
+	if len(logger.debug) != 1 || !strings.Contains(logger.debug[0], `"event":"Signup"`) {
+		t.Error("Expected the payload at debug level, got", logger.debug)
+	}
+}
+
+func TestBufferedHTTPConsumer(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var transport = NewMixPanel("token", WithBaseURL(server.URL))
+	var buffered = NewBufferedConsumer(transport.HTTPConsumer(), AsyncOptions{})
+	var mixpanel = NewMixPanel("token", WithConsumer(buffered))
+	for i := 0; i < 3; i++ {
+		if err := mixpanel.TrackEventOnly("Test BufferedConsumer"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mixpanel.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if server.requestCount() != 1 || len(server.payloads[0].([]interface{})) != 3 {
+		t.Error("Expected one batch of 3 events", server.payloads)
+	}
+}
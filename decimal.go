@@ -0,0 +1,34 @@
+package mixpanel
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// decimalPattern matches the JSON number syntax.
+var decimalPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// Decimal is a number kept in its decimal string form, such as "19.99", so it is sent to mixpanel exactly
+// instead of going through a float64. Create it with ParseDecimal, it can be used with ProfileAddDecimal and ProfileUpdate.Add.
+type Decimal string
+
+// ParseDecimal checks value is a number and returns it as a Decimal.
+func ParseDecimal(value string) (Decimal, error) {
+	if !decimalPattern.MatchString(value) {
+		return "", fmt.Errorf("Invalid decimal %q", value)
+	}
+	return Decimal(value), nil
+}
+
+// MarshalJSON encodes the decimal as a JSON number with the digits unchanged.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if !decimalPattern.MatchString(string(d)) {
+		return nil, fmt.Errorf("Invalid decimal %q", string(d))
+	}
+	return []byte(d), nil
+}
+
+// String returns the decimal as written.
+func (d Decimal) String() string {
+	return string(d)
+}
@@ -0,0 +1,47 @@
+package mixpanel
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	for _, value := range []string{"0", "19.99", "-0.01", "12345678901234567890.123456789", "1e3"} {
+		if _, err := ParseDecimal(value); err != nil {
+			t.Error("Expected", value, "to be valid, got", err)
+		}
+	}
+	for _, value := range []string{"", "abc", "1.", ".5", "01", "1,5", "NaN"} {
+		if _, err := ParseDecimal(value); err == nil {
+			t.Error("Expected", value, "to be invalid")
+		}
+	}
+}
+
+func TestDecimalMarshalJSON(t *testing.T) {
+	data, err := json.Marshal(map[string]interface{}{"$add": map[string]Decimal{"lifetime_spend": "0.10000000000000000001"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"$add":{"lifetime_spend":0.10000000000000000001}}` {
+		t.Error("Unexpected encoding", string(data))
+	}
+	if _, err := json.Marshal(Decimal("abc")); err == nil {
+		t.Error("Expected an error encoding an invalid decimal")
+	}
+}
+
+func TestProfileAddDecimal(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	var amount, _ = ParseDecimal("19.99")
+	if err := mixpanel.ProfileAddDecimal("User 0001", map[string]Decimal{"lifetime_spend": amount}); err != nil {
+		t.Fatal(err)
+	}
+	if add := consumer.payloads[0]["$add"].(map[string]Decimal); add["lifetime_spend"] != "19.99" {
+		t.Error("Unexpected $add", add)
+	}
+	if _, err := NewProfileUpdate("User 0001").Add("lifetime_spend", amount).Operations(); err != nil {
+		t.Error("Expected a Decimal to be accepted by Add, got", err)
+	}
+}
@@ -0,0 +1,77 @@
+package mixpanel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Sentinel errors matched by APIError with errors.Is.
+var (
+	// ErrRateLimited matches responses with status 429 Too Many Requests.
+	ErrRateLimited = errors.New("Mixpanel rate limit exceeded")
+	// ErrUnauthorized matches responses with status 401 Unauthorized or 403 Forbidden.
+	ErrUnauthorized = errors.New("Mixpanel request unauthorized")
+	// ErrPayloadTooLarge matches responses with status 413 Request Entity Too Large.
+	ErrPayloadTooLarge = errors.New("Mixpanel payload too large")
+	// ErrRejected matches responses which mixpanel answered with "0", meaning the data was not accepted.
+	ErrRejected = errors.New("Mixpanel rejected the data")
+)
+
+// APIError is returned when mixpanel answers a request with an error.
+type APIError struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Endpoint is the path of the request, for example "/track/".
+	Endpoint string
+	// Body is the raw response body.
+	Body string
+	// Message is the "error" field of a JSON response, such as those returned in verbose mode.
+	Message string
+	// RetryAfter is the delay asked for by a Retry-After header.
+	RetryAfter time.Duration
+}
+
+// Error describes the failed request.
+func (e *APIError) Error() string {
+	var reason = e.Message
+	if reason == "" {
+		reason = e.Body
+	}
+	return fmt.Sprintf("Error response from mixpanel server (status %d, endpoint %s): %s", e.StatusCode, e.Endpoint, reason)
+}
+
+// Is reports whether the error matches one of the sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrPayloadTooLarge:
+		return e.StatusCode == http.StatusRequestEntityTooLarge
+	case ErrRejected:
+		return e.StatusCode < http.StatusBadRequest
+	}
+	return false
+}
+
+// newAPIError builds an APIError from the response and its body, parsing the "error" field of JSON bodies.
+// The endpoint is the path of the request that was sent, as a Doer need not set response.Request.
+func newAPIError(request *http.Request, response *http.Response, body []byte) *APIError {
+	var apiError = &APIError{
+		StatusCode: response.StatusCode,
+		Endpoint:   request.URL.Path,
+		Body:       string(body),
+		RetryAfter: parseRetryAfter(response.Header.Get("Retry-After")),
+	}
+	var parsed struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &parsed) == nil {
+		apiError.Message = parsed.Error
+	}
+	return apiError
+}
@@ -0,0 +1,88 @@
+package mixpanel
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	var server, _ = newScriptedServer(t, scriptedResponse{status: http.StatusOK, body: `{"status":0,"error":"token missing"}`})
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	var err = mixpanel.TrackEventOnly("Test APIError")
+	var apiError *APIError
+	if !errors.As(err, &apiError) {
+		t.Fatal("Expected an APIError, got", err)
+	}
+	if apiError.StatusCode != http.StatusOK || apiError.Endpoint != trackPath || apiError.Message != "token missing" {
+		t.Error("Unexpected APIError", apiError)
+	}
+	if !errors.Is(err, ErrRejected) || errors.Is(err, ErrRateLimited) {
+		t.Error("Unexpected sentinel match", err)
+	}
+}
+
+func TestAPIErrorSentinels(t *testing.T) {
+	var cases = map[int]error{
+		http.StatusTooManyRequests:       ErrRateLimited,
+		http.StatusUnauthorized:          ErrUnauthorized,
+		http.StatusForbidden:             ErrUnauthorized,
+		http.StatusRequestEntityTooLarge: ErrPayloadTooLarge,
+	}
+	for status, sentinel := range cases {
+		var server, _ = newScriptedServer(t, scriptedResponse{status: status, body: "0"})
+		var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+		var err = mixpanel.ProfileDelete("User 0001")
+		if !errors.Is(err, sentinel) {
+			t.Error("Status", status, "expected to match", sentinel, "got", err)
+		}
+		if errors.Is(err, ErrRejected) {
+			t.Error("Status", status, "must not match ErrRejected")
+		}
+	}
+}
+
+func TestAPIErrorThroughBatch(t *testing.T) {
+	var server, _ = newScriptedServer(t, scriptedResponse{status: http.StatusTooManyRequests, body: "0"})
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	var err = mixpanel.TrackEvents(testEvents(60))
+	if !errors.Is(err, ErrRateLimited) {
+		t.Error("Expected the batch error to match ErrRateLimited, got", err)
+	}
+}
+
+func TestImportErrorUnwrap(t *testing.T) {
+	var server, _ = newImportServer(t, http.StatusUnauthorized, `{"error":"Invalid credentials"}`)
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithAPISecret("secret"))
+	var _, err = mixpanel.Import(importEvents(1), ImportOptions{})
+	var apiError *APIError
+	if !errors.As(err, &apiError) || apiError.Message != "Invalid credentials" {
+		t.Error("Expected an APIError, got", err)
+	}
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Error("Expected to match ErrUnauthorized, got", err)
+	}
+}
+
+// doerFunc lets a function act as a Doer.
+type doerFunc func(*http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestAPIErrorEndpointCustomDoer(t *testing.T) {
+	var doer = doerFunc(func(request *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusUnauthorized, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("bad"))}, nil
+	})
+	var mixpanel = NewMixPanel("token", WithHTTPClient(doer), WithAPISecret("secret"))
+	var apiError *APIError
+	if err := mixpanel.TrackEventOnly("Test APIError"); !errors.As(err, &apiError) || apiError.Endpoint != "/track/" {
+		t.Error("Expected the /track/ endpoint, got", err)
+	}
+	if _, err := mixpanel.Import(importEvents(1), ImportOptions{}); !errors.As(err, &apiError) || apiError.Endpoint != "/import" {
+		t.Error("Expected the /import endpoint, got", err)
+	}
+}
@@ -0,0 +1,110 @@
+package mixpanel
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Event is a single event for Track, TrackEvents and Import.
+// Build it with NewEvent and the setter methods, or fill in the fields directly.
+// Properties are sent as given, the token is added automatically.
+type Event struct {
+	Name       string
+	Properties map[string]interface{}
+}
+
+// NewEvent creates an event with the given name and no properties.
+// The setters return the event so calls can be chained, later calls overwrite earlier values for the same property.
+func NewEvent(name string) *Event {
+	return &Event{Name: name, Properties: make(map[string]interface{})}
+}
+
+// Prop sets a single property.
+func (e *Event) Prop(key string, value interface{}) *Event {
+	if e.Properties == nil {
+		e.Properties = make(map[string]interface{})
+	}
+	e.Properties[key] = value
+	return e
+}
+
+// Props sets every property in properties.
+func (e *Event) Props(properties map[string]interface{}) *Event {
+	for key, value := range properties {
+		e.Prop(key, value)
+	}
+	return e
+}
+
+// DistinctID sets the user the event belongs to.
+func (e *Event) DistinctID(distinctID string) *Event {
+	return e.Prop("distinct_id", distinctID)
+}
+
+// DeviceID sets the $device_id of an anonymous device for projects using Simplified ID Merge.
+// When no distinct_id is set, events with only a device id are sent with the distinct_id "$device:<device id>".
+func (e *Event) DeviceID(deviceID string) *Event {
+	return e.Prop("$device_id", deviceID)
+}
+
+// UserID sets the $user_id of an identified user for projects using Simplified ID Merge.
+// When no distinct_id is set, the user id is used as the distinct_id.
+func (e *Event) UserID(userID string) *Event {
+	return e.Prop("$user_id", userID)
+}
+
+// Group sets the group property groupKey, such as "company_id", so the event counts towards the group groupID
+// in Group Analytics. groupID may also be a list of ids when the event belongs to several groups.
+func (e *Event) Group(groupKey string, groupID interface{}) *Event {
+	return e.Prop(groupKey, groupID)
+}
+
+// Time sets when the event happened.
+func (e *Event) Time(timeStamp time.Time) *Event {
+	return e.Prop("time", timeStamp.Unix())
+}
+
+// IP sets the ip address mixpanel uses to find the location of the event.
+func (e *Event) IP(ipAddress string) *Event {
+	return e.Prop("ip", ipAddress)
+}
+
+// InsertID sets the $insert_id mixpanel uses to drop duplicates of the event.
+func (e *Event) InsertID(insertID string) *Event {
+	return e.Prop("$insert_id", insertID)
+}
+
+// Track tracks the event.
+func (m *MixPanel) Track(e *Event) error {
+	return m.TrackContext(context.Background(), e)
+}
+
+// TrackContext is like Track but uses ctx for the outgoing request.
+func (m *MixPanel) TrackContext(ctx context.Context, e *Event) error {
+	return m.event(ctx, m.eventPacket(e))
+}
+
+// eventPacket builds the /track payload of the event.
+func (m *MixPanel) eventPacket(e *Event) map[string]interface{} {
+	var properties = mergeMapsCopy(m.withSuperProperties(e.Properties), map[string]interface{}{"token": m.Token})
+	deriveDistinctID(properties)
+	m.ensureInsertID(e.Name, properties)
+	return map[string]interface{}{
+		"event":      e.Name,
+		"properties": properties,
+	}
+}
+
+// deriveDistinctID sets distinct_id from $user_id or $device_id when it is missing, following the Simplified ID Merge rules.
+// An explicit distinct_id is kept.
+func deriveDistinctID(properties map[string]interface{}) {
+	if distinctID, ok := properties["distinct_id"]; ok && distinctID != nil && distinctID != "" {
+		return
+	}
+	if userID, ok := properties["$user_id"]; ok && userID != nil && userID != "" {
+		properties["distinct_id"] = userID
+	} else if deviceID, ok := properties["$device_id"]; ok && deviceID != nil && deviceID != "" {
+		properties["distinct_id"] = fmt.Sprint("$device:", deviceID)
+	}
+}
@@ -0,0 +1,93 @@
+package mixpanel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewEvent(t *testing.T) {
+	var timeStamp = time.Date(2018, 3, 21, 10, 0, 0, 0, time.UTC)
+	var e = NewEvent("Signup").
+		Props(map[string]interface{}{"plan": "free", "ip": "overwritten"}).
+		DistinctID("User 0001").
+		Time(timeStamp).
+		IP("64.2.4.1").
+		InsertID("signup-1").
+		Prop("plan", "pro")
+	var expected = map[string]interface{}{
+		"plan":        "pro",
+		"distinct_id": "User 0001",
+		"time":        timeStamp.Unix(),
+		"ip":          "64.2.4.1",
+		"$insert_id":  "signup-1",
+	}
+	if e.Name != "Signup" || len(e.Properties) != len(expected) {
+		t.Fatal("Unexpected event", e)
+	}
+	for key, value := range expected {
+		if e.Properties[key] != value {
+			t.Error("Property", key, "expected", value, "got", e.Properties[key])
+		}
+	}
+}
+
+func TestEventLiteralProp(t *testing.T) {
+	var e = &Event{Name: "Literal"}
+	if e.Prop("a", 1).Properties["a"] != 1 {
+		t.Error("Prop must create the properties map")
+	}
+}
+
+func TestTrack(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	if err := mixpanel.Track(NewEvent("Signup").DistinctID("User 0001").Prop("plan", "pro")); err != nil {
+		t.Fatal(err)
+	}
+	var payload = consumer.payloads[0]
+	var properties = payload["properties"].(map[string]interface{})
+	if payload["event"] != "Signup" || properties["token"] != "token" || properties["plan"] != "pro" || properties["$insert_id"] == nil {
+		t.Error("Unexpected payload", payload)
+	}
+}
+
+func TestTrackEventPrecedence(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	var parameters = map[string]interface{}{"distinct_id": "Parameter", "token": "Parameter", "key": "value"}
+	if err := mixpanel.TrackEventForUserWithParameters("Test TrackEvent", "User 0001", parameters); err != nil {
+		t.Fatal(err)
+	}
+	var properties = consumer.payloads[0]["properties"].(map[string]interface{})
+	if properties["distinct_id"] != "User 0001" || properties["token"] != "token" || properties["key"] != "value" || properties["time"] == nil {
+		t.Error("Unexpected properties", properties)
+	}
+	if _, ok := parameters["time"]; ok {
+		t.Error("The parameters map must not be modified")
+	}
+}
+
+func TestTrackSimplifiedIDMerge(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	var events = []*Event{
+		NewEvent("Page View").DeviceID("device-1234"),
+		NewEvent("Login").DeviceID("device-1234").UserID("User 0001"),
+		NewEvent("Purchase").DeviceID("device-1234").UserID("User 0001").DistinctID("Explicit"),
+	}
+	for _, event := range events {
+		if err := mixpanel.Track(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var expected = []string{"$device:device-1234", "User 0001", "Explicit"}
+	for i, distinctID := range expected {
+		var properties = consumer.payloads[i]["properties"].(map[string]interface{})
+		if properties["distinct_id"] != distinctID || properties["$device_id"] != "device-1234" {
+			t.Error("Expected distinct_id", distinctID, "got", properties)
+		}
+	}
+	if _, ok := events[0].Properties["distinct_id"]; ok {
+		t.Error("The event properties must not be modified")
+	}
+}
@@ -0,0 +1,74 @@
+package mixpanel
+
+import "context"
+
+// GroupSet follows the http documentation of the /groups endpoint, it works like ProfileSet on the group profile
+// identified by groupKey, the group property such as "company_id", and groupID, the value of that property.
+func (m *MixPanel) GroupSet(groupKey string, groupID string, attributes map[string]interface{}) error {
+	return m.GroupSetContext(context.Background(), groupKey, groupID, attributes)
+}
+
+// GroupSetContext is like GroupSet but uses ctx for the outgoing request.
+func (m *MixPanel) GroupSetContext(ctx context.Context, groupKey string, groupID string, attributes map[string]interface{}) error {
+	return m.group(ctx, m.groupRecord(groupKey, groupID, OperatorSet, attributes))
+}
+
+// GroupSetOnce works like ProfileSetOnce on the group profile, existing property values are not overwritten.
+func (m *MixPanel) GroupSetOnce(groupKey string, groupID string, attributes map[string]interface{}) error {
+	return m.GroupSetOnceContext(context.Background(), groupKey, groupID, attributes)
+}
+
+// GroupSetOnceContext is like GroupSetOnce but uses ctx for the outgoing request.
+func (m *MixPanel) GroupSetOnceContext(ctx context.Context, groupKey string, groupID string, attributes map[string]interface{}) error {
+	return m.group(ctx, m.groupRecord(groupKey, groupID, OperatorSetOnce, attributes))
+}
+
+// GroupUnion works like ProfileUnion on the group profile, the list values are merged into the existing lists without duplicates.
+func (m *MixPanel) GroupUnion(groupKey string, groupID string, attributes map[string]interface{}) error {
+	return m.GroupUnionContext(context.Background(), groupKey, groupID, attributes)
+}
+
+// GroupUnionContext is like GroupUnion but uses ctx for the outgoing request.
+func (m *MixPanel) GroupUnionContext(ctx context.Context, groupKey string, groupID string, attributes map[string]interface{}) error {
+	return m.group(ctx, m.groupRecord(groupKey, groupID, OperatorUnion, attributes))
+}
+
+// GroupRemove works like ProfileRemove on the group profile, the values are removed from the existing lists.
+func (m *MixPanel) GroupRemove(groupKey string, groupID string, attributes map[string]interface{}) error {
+	return m.GroupRemoveContext(context.Background(), groupKey, groupID, attributes)
+}
+
+// GroupRemoveContext is like GroupRemove but uses ctx for the outgoing request.
+func (m *MixPanel) GroupRemoveContext(ctx context.Context, groupKey string, groupID string, attributes map[string]interface{}) error {
+	return m.group(ctx, m.groupRecord(groupKey, groupID, OperatorRemove, attributes))
+}
+
+// GroupUnset works like ProfileUnset on the group profile, the properties are permanently removed.
+func (m *MixPanel) GroupUnset(groupKey string, groupID string, keyList []string) error {
+	return m.GroupUnsetContext(context.Background(), groupKey, groupID, keyList)
+}
+
+// GroupUnsetContext is like GroupUnset but uses ctx for the outgoing request.
+func (m *MixPanel) GroupUnsetContext(ctx context.Context, groupKey string, groupID string, keyList []string) error {
+	return m.group(ctx, m.groupRecord(groupKey, groupID, OperatorUnset, keyList))
+}
+
+// GroupDelete permanently deletes the group profile along with all of its properties.
+func (m *MixPanel) GroupDelete(groupKey string, groupID string) error {
+	return m.GroupDeleteContext(context.Background(), groupKey, groupID)
+}
+
+// GroupDeleteContext is like GroupDelete but uses ctx for the outgoing request.
+func (m *MixPanel) GroupDeleteContext(ctx context.Context, groupKey string, groupID string) error {
+	return m.group(ctx, m.groupRecord(groupKey, groupID, OperatorDelete, ""))
+}
+
+// groupRecord builds the /groups payload for one operator.
+func (m *MixPanel) groupRecord(groupKey string, groupID string, operator string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"$token":     m.Token,
+		"$group_key": groupKey,
+		"$group_id":  groupID,
+		operator:     value,
+	}
+}
@@ -0,0 +1,66 @@
+package mixpanel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupSet(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	if err := mixpanel.GroupSet("company_id", "Acme", map[string]interface{}{"plan": "enterprise"}); err != nil {
+		t.Fatal(err)
+	}
+	if path := server.requests[0].URL.Path; path != "/groups/" {
+		t.Error("Expected a request to /groups/, got", path)
+	}
+	var expected = map[string]interface{}{
+		"$token":     "token",
+		"$group_key": "company_id",
+		"$group_id":  "Acme",
+		"$set":       map[string]interface{}{"plan": "enterprise"},
+	}
+	if payload := server.lastPayload(t); !reflect.DeepEqual(payload, expected) {
+		t.Error("Expected", expected, "got", payload)
+	}
+}
+
+func TestGroupOperators(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	var attributes = map[string]interface{}{"tags": "beta"}
+	var calls = []struct {
+		operator string
+		call     func() error
+	}{
+		{OperatorSetOnce, func() error { return mixpanel.GroupSetOnce("company_id", "Acme", attributes) }},
+		{OperatorUnion, func() error { return mixpanel.GroupUnion("company_id", "Acme", attributes) }},
+		{OperatorRemove, func() error { return mixpanel.GroupRemove("company_id", "Acme", attributes) }},
+		{OperatorUnset, func() error { return mixpanel.GroupUnset("company_id", "Acme", []string{"tags"}) }},
+		{OperatorDelete, func() error { return mixpanel.GroupDelete("company_id", "Acme") }},
+	}
+	for i, call := range calls {
+		if err := call.call(); err != nil {
+			t.Fatal(err)
+		}
+		var payload = consumer.payloads[i]
+		if consumer.endpoints[i] != EndpointGroups || payload["$group_id"] != "Acme" {
+			t.Error("Unexpected payload", consumer.endpoints[i], payload)
+		}
+		if _, ok := payload[call.operator]; !ok {
+			t.Error("Expected operator", call.operator, "got", payload)
+		}
+	}
+}
+
+func TestEventGroup(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	if err := mixpanel.Track(NewEvent("Invoice Paid").DistinctID("User 0001").Group("company_id", "Acme")); err != nil {
+		t.Fatal(err)
+	}
+	var properties = consumer.payloads[0]["properties"].(map[string]interface{})
+	if properties["company_id"] != "Acme" {
+		t.Error("Expected the group property, got", properties)
+	}
+}
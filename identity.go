@@ -0,0 +1,77 @@
+package mixpanel
+
+import (
+	"context"
+	"errors"
+)
+
+// CreateAlias tracks a $create_alias event which makes alias another id for the user with distinctID.
+// It is needed by projects still on the original ID merge API, alias must differ from distinctID.
+func (m *MixPanel) CreateAlias(distinctID string, alias string) error {
+	return m.CreateAliasContext(context.Background(), distinctID, alias)
+}
+
+// CreateAliasContext is like CreateAlias but uses ctx for the outgoing request.
+func (m *MixPanel) CreateAliasContext(ctx context.Context, distinctID string, alias string) error {
+	if distinctID == "" || alias == "" {
+		return errors.New("Alias and distinct id cannot be empty")
+	}
+	if distinctID == alias {
+		return errors.New("Alias must differ from the distinct id")
+	}
+	return m.TrackContext(ctx, NewEvent("$create_alias").DistinctID(distinctID).Prop("alias", alias))
+}
+
+// Identify tracks an $identify event which links the anonymous id used before the user logged in, such as
+// a device id, to the id the user is known by afterwards. anonID must differ from identifiedID.
+func (m *MixPanel) Identify(identifiedID string, anonID string) error {
+	return m.IdentifyContext(context.Background(), identifiedID, anonID)
+}
+
+// IdentifyContext is like Identify but uses ctx for the outgoing request.
+func (m *MixPanel) IdentifyContext(ctx context.Context, identifiedID string, anonID string) error {
+	if identifiedID == "" || anonID == "" {
+		return errors.New("Identified id and anonymous id cannot be empty")
+	}
+	if identifiedID == anonID {
+		return errors.New("Anonymous id must differ from the identified id")
+	}
+	var event = NewEvent("$identify").
+		DistinctID(identifiedID).
+		Prop("$identified_id", identifiedID).
+		Prop("$anon_id", anonID)
+	return m.TrackContext(ctx, event)
+}
+
+// Merge sends a $merge event which joins two distinct ids into one user.
+// Mixpanel only accepts it on the /import endpoint, so it requires WithAPISecret or WithServiceAccount.
+func (m *MixPanel) Merge(distinctID1 string, distinctID2 string) error {
+	return m.MergeContext(context.Background(), distinctID1, distinctID2)
+}
+
+// MergeContext is like Merge but uses ctx for the outgoing request.
+func (m *MixPanel) MergeContext(ctx context.Context, distinctID1 string, distinctID2 string) error {
+	if m.secret == "" {
+		return ErrNoCredentials
+	}
+	if distinctID1 == "" || distinctID2 == "" {
+		return errors.New("Distinct ids to merge cannot be empty")
+	}
+	if distinctID1 == distinctID2 {
+		return errors.New("Distinct ids to merge must differ")
+	}
+	var packet = map[string]interface{}{
+		"event": "$merge",
+		"properties": map[string]interface{}{
+			"$distinct_ids": []string{distinctID1, distinctID2},
+		},
+	}
+	if m.logger != nil && m.debug {
+		m.logger.Debug("Sending mixpanel merge", "payloads", packet)
+	}
+	_, err := m.importChunk(ctx, []map[string]interface{}{packet}, ImportOptions{})
+	if err != nil && m.logger != nil {
+		m.logger.Error("Mixpanel merge failed", "error", err)
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package mixpanel
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCreateAlias(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	if err := mixpanel.CreateAlias("User 0001", "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	var properties = consumer.payloads[0]["properties"].(map[string]interface{})
+	if consumer.payloads[0]["event"] != "$create_alias" || properties["distinct_id"] != "User 0001" || properties["alias"] != "user@example.com" {
+		t.Error("Unexpected payload", consumer.payloads[0])
+	}
+	if err := mixpanel.CreateAlias("User 0001", "User 0001"); err == nil {
+		t.Error("Expected an error for an alias equal to the distinct id")
+	}
+	if len(consumer.payloads) != 1 {
+		t.Error("Expected invalid aliases not to be sent")
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	if err := mixpanel.Identify("User 0001", "device-1234"); err != nil {
+		t.Fatal(err)
+	}
+	var properties = consumer.payloads[0]["properties"].(map[string]interface{})
+	var expected = map[string]interface{}{
+		"distinct_id":    "User 0001",
+		"$identified_id": "User 0001",
+		"$anon_id":       "device-1234",
+	}
+	for key, value := range expected {
+		if properties[key] != value {
+			t.Error("Property", key, "expected", value, "got", properties[key])
+		}
+	}
+	if err := mixpanel.Identify("User 0001", ""); err == nil {
+		t.Error("Expected an error for an empty anonymous id")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	var server, requests = newImportServer(t, 200, "")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithAPISecret("secret"))
+	if err := mixpanel.Merge("User 0001", "device-1234"); err != nil {
+		t.Fatal(err)
+	}
+	var request = (*requests)[0]
+	if request.username != "secret" {
+		t.Error("Expected basic auth with the api secret, got", request.username)
+	}
+	var properties = request.events[0]["properties"].(map[string]interface{})
+	if request.events[0]["event"] != "$merge" || !reflect.DeepEqual(properties["$distinct_ids"], []interface{}{"User 0001", "device-1234"}) {
+		t.Error("Unexpected payload", request.events[0])
+	}
+}
+
+func TestMergeInvalid(t *testing.T) {
+	if err := NewMixPanel("token").Merge("User 0001", "device-1234"); !errors.Is(err, ErrNoCredentials) {
+		t.Error("Expected ErrNoCredentials, got", err)
+	}
+	var mixpanel = NewMixPanel("token", WithAPISecret("secret"))
+	if err := mixpanel.Merge("User 0001", "User 0001"); err == nil {
+		t.Error("Expected an error for identical distinct ids")
+	}
+	if err := mixpanel.Merge("", "User 0001"); err == nil {
+		t.Error("Expected an error for an empty distinct id")
+	}
+}
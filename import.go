@@ -0,0 +1,206 @@
+package mixpanel
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// maxImportBatchSize is the largest number of events mixpanel accepts in a single /import request.
+const maxImportBatchSize = 2000
+
+// ErrNoCredentials is returned by calls which need an API secret or service account when neither was given.
+var ErrNoCredentials = errors.New("Mixpanel API secret or service account required")
+
+// WithAPISecret authenticates calls to the /import endpoint with the project's API secret.
+func WithAPISecret(secret string) Option {
+	return func(m *MixPanel) {
+		m.secret = secret
+	}
+}
+
+// WithServiceAccount authenticates calls to the /import endpoint with a service account of the given project.
+func WithServiceAccount(username string, secret string, projectID string) Option {
+	return func(m *MixPanel) {
+		m.account = username
+		m.secret = secret
+		m.project = projectID
+	}
+}
+
+// ImportOptions changes how Import sends events.
+type ImportOptions struct {
+	// Gzip compresses the request bodies.
+	Gzip bool
+	// Strict makes mixpanel validate every event and reject the batch with per record failures.
+	Strict bool
+}
+
+// FailedRecord describes an event the /import endpoint rejected in strict mode.
+// Index is the position of the event in the request.
+type FailedRecord struct {
+	Index    int    `json:"index"`
+	InsertID string `json:"$insert_id"`
+	Field    string `json:"field"`
+	Message  string `json:"message"`
+}
+
+// ImportError is returned when the /import endpoint rejects a request, it wraps the APIError of the response.
+type ImportError struct {
+	APIError
+	Imported      int
+	FailedRecords []FailedRecord
+}
+
+// Error returns the reason given by mixpanel and the number of failed records.
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("%s (%d failed records)", e.APIError.Error(), len(e.FailedRecords))
+}
+
+// Unwrap returns the APIError of the response.
+func (e *ImportError) Unwrap() error {
+	return &e.APIError
+}
+
+// importResponse is the JSON body returned by the /import endpoint.
+type importResponse struct {
+	Imported      int            `json:"num_records_imported"`
+	FailedRecords []FailedRecord `json:"failed_records"`
+}
+
+// Import sends events of any age through the /import endpoint, which unlike /track accepts events older than five days.
+// Every event needs a "time" property, events without an $insert_id are given one.
+// It requires WithAPISecret or WithServiceAccount. Events are sent in chunks of 2000 and the number imported is returned,
+// a *BatchError of *ImportError describes the chunks which failed.
+func (m *MixPanel) Import(events []Event, options ImportOptions) (int, error) {
+	return m.ImportContext(context.Background(), events, options)
+}
+
+// ImportContext is like Import but uses ctx for the outgoing requests.
+func (m *MixPanel) ImportContext(ctx context.Context, events []Event, options ImportOptions) (int, error) {
+	if m.secret == "" {
+		return 0, ErrNoCredentials
+	}
+	var packets = make([]map[string]interface{}, len(events))
+	for i, event := range events {
+		var properties = m.withSuperProperties(event.Properties)
+		switch timeStamp := properties["time"].(type) {
+		case nil:
+			return 0, fmt.Errorf("Event %d (%s) has no time property", i, event.Name)
+		case time.Time:
+			properties["time"] = timeStamp.Unix()
+		}
+		deriveDistinctID(properties)
+		m.ensureInsertID(event.Name, properties)
+		packets[i] = map[string]interface{}{
+			"event":      event.Name,
+			"properties": properties,
+		}
+	}
+	var imported = 0
+	var batchError = &BatchError{Total: (len(packets) + maxImportBatchSize - 1) / maxImportBatchSize}
+	for chunk, start := 0, 0; start < len(packets); chunk, start = chunk+1, start+maxImportBatchSize {
+		var end = start + maxImportBatchSize
+		if end > len(packets) {
+			end = len(packets)
+		}
+		if m.logger != nil && m.debug {
+			m.logger.Debug("Importing mixpanel events", "payloads", packets[start:end])
+		}
+		count, err := m.importChunk(ctx, packets[start:end], options)
+		imported += count
+		if err != nil {
+			if m.logger != nil {
+				m.logger.Error("Mixpanel import failed", "chunk", chunk, "count", end-start, "error", err)
+			}
+			batchError.Chunks = append(batchError.Chunks, &ChunkError{Chunk: chunk, Start: start, End: end, Err: err})
+		}
+	}
+	if len(batchError.Chunks) > 0 {
+		return imported, batchError
+	}
+	return imported, nil
+}
+
+// importChunk sends one request to the /import endpoint, retrying it when a retry policy is set.
+func (m *MixPanel) importChunk(ctx context.Context, packets []map[string]interface{}, options ImportOptions) (int, error) {
+	body, err := json.Marshal(packets)
+	if err != nil {
+		return 0, err
+	}
+	if options.Gzip {
+		var buffer bytes.Buffer
+		var writer = gzip.NewWriter(&buffer)
+		if _, err := writer.Write(body); err != nil {
+			return 0, err
+		}
+		if err := writer.Close(); err != nil {
+			return 0, err
+		}
+		body = buffer.Bytes()
+	}
+	var query = url.Values{}
+	if options.Strict {
+		query.Set("strict", "1")
+	}
+	if m.project != "" {
+		query.Set("project_id", m.project)
+	}
+	var endpointURL = m.endpoint(importPath)
+	if len(query) > 0 {
+		endpointURL += "?" + query.Encode()
+	}
+	var imported = 0
+	err = m.withRetries(ctx, true, func() error {
+		var err error
+		imported, err = m.doImportCall(ctx, endpointURL, body, options.Gzip)
+		return err
+	})
+	return imported, err
+}
+
+// doImportCall makes a single request to the /import endpoint and parses the response.
+func (m *MixPanel) doImportCall(ctx context.Context, endpointURL string, body []byte, gzipped bool) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpointURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if gzipped {
+		request.Header.Set("Content-Encoding", "gzip")
+	}
+	if m.account != "" {
+		request.SetBasicAuth(m.account, m.secret)
+	} else {
+		request.SetBasicAuth(m.secret, "")
+	}
+	response, err := m.httpClient().Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return 0, err
+	}
+	var result importResponse
+	var parseErr = json.Unmarshal(responseBody, &result)
+	if response.StatusCode == http.StatusOK {
+		return result.Imported, parseErr
+	}
+	if response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError {
+		return 0, newAPIError(request, response, responseBody)
+	}
+	return result.Imported, &ImportError{
+		APIError:      *newAPIError(request, response, responseBody),
+		Imported:      result.Imported,
+		FailedRecords: result.FailedRecords,
+	}
+}
@@ -0,0 +1,138 @@
+package mixpanel
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// importRequest is what the import test server saw of a request.
+type importRequest struct {
+	username string
+	password string
+	query    string
+	events   []map[string]interface{}
+}
+
+// newImportServer starts a server which records import requests and answers with status and response.
+func newImportServer(t *testing.T, status int, response string) (*httptest.Server, *[]importRequest) {
+	var requests []importRequest
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reader io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gzipReader, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			reader = gzipReader
+		}
+		var request importRequest
+		request.username, request.password, _ = r.BasicAuth()
+		request.query = r.URL.RawQuery
+		if err := json.NewDecoder(reader).Decode(&request.events); err != nil {
+			t.Error(err)
+		}
+		requests = append(requests, request)
+		w.WriteHeader(status)
+		if response == "" {
+			fmt.Fprintf(w, `{"code":200,"num_records_imported":%d,"status":"OK"}`, len(request.events))
+			return
+		}
+		fmt.Fprint(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func importEvents(count int) []Event {
+	var events = make([]Event, count)
+	for i := range events {
+		events[i] = Event{
+			Name:       "Test Import",
+			Properties: map[string]interface{}{"distinct_id": "User 0001", "time": time.Now().AddDate(-1, 0, 0)},
+		}
+	}
+	return events
+}
+
+func TestImport(t *testing.T) {
+	var server, requests = newImportServer(t, http.StatusOK, "")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithAPISecret("secret"))
+	imported, err := mixpanel.Import(importEvents(2500), ImportOptions{Gzip: true, Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 2500 || len(*requests) != 2 {
+		t.Fatal("Unexpected import", imported, len(*requests))
+	}
+	var request = (*requests)[0]
+	if request.username != "secret" || request.password != "" || request.query != "strict=1" {
+		t.Error("Unexpected request", request.username, request.password, request.query)
+	}
+	var properties = request.events[0]["properties"].(map[string]interface{})
+	if _, ok := properties["time"].(float64); !ok || properties["$insert_id"] == nil {
+		t.Error("Unexpected properties", properties)
+	}
+}
+
+func TestImportSimplifiedIDMerge(t *testing.T) {
+	var server, requests = newImportServer(t, http.StatusOK, "")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithAPISecret("secret"))
+	var event = NewEvent("Test Import").DeviceID("device-1234").Time(time.Now().AddDate(-1, 0, 0))
+	if _, err := mixpanel.Import([]Event{*event}, ImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var properties = (*requests)[0].events[0]["properties"].(map[string]interface{})
+	if properties["distinct_id"] != "$device:device-1234" {
+		t.Error("Expected a distinct_id derived from the device id, got", properties["distinct_id"])
+	}
+}
+
+func TestImportServiceAccount(t *testing.T) {
+	var server, requests = newImportServer(t, http.StatusOK, "")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithServiceAccount("account", "secret", "12345"))
+	if _, err := mixpanel.Import(importEvents(1), ImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var request = (*requests)[0]
+	if request.username != "account" || request.password != "secret" || request.query != "project_id=12345" {
+		t.Error("Unexpected request", request.username, request.password, request.query)
+	}
+}
+
+func TestImportFailedRecords(t *testing.T) {
+	var response = `{"code":400,"error":"some data points in the request failed validation","failed_records":[{"index":1,"$insert_id":"abc","field":"properties.time","message":"'properties.time' is invalid"}],"num_records_imported":1,"status":"Bad Request"}`
+	var server, _ = newImportServer(t, http.StatusBadRequest, response)
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithAPISecret("secret"))
+	imported, err := mixpanel.Import(importEvents(2), ImportOptions{Strict: true})
+	var importError *ImportError
+	if !errors.As(err, &importError) {
+		t.Fatal("Expected an ImportError, got", err)
+	}
+	if imported != 1 || importError.StatusCode != http.StatusBadRequest || len(importError.FailedRecords) != 1 {
+		t.Fatal("Unexpected import error", imported, importError)
+	}
+	var record = importError.FailedRecords[0]
+	if record.Index != 1 || record.InsertID != "abc" || record.Field != "properties.time" {
+		t.Error("Unexpected failed record", record)
+	}
+}
+
+func TestImportValidation(t *testing.T) {
+	var mixpanel = NewMixPanel("token")
+	if _, err := mixpanel.Import(importEvents(1), ImportOptions{}); err != ErrNoCredentials {
+		t.Error("Expected ErrNoCredentials, got", err)
+	}
+	mixpanel = NewMixPanel("token", WithAPISecret("secret"))
+	var events = []Event{{Name: "Test Import", Properties: map[string]interface{}{"distinct_id": "User 0001"}}}
+	if _, err := mixpanel.Import(events, ImportOptions{}); err == nil {
+		t.Error("Expected an error for an event without time")
+	}
+}
@@ -0,0 +1,66 @@
+package mixpanel
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// InsertIDGenerator returns the $insert_id for an event which was tracked without one.
+// Mixpanel uses $insert_id to drop duplicate events, it must be at most 36 alphanumeric or '-' characters.
+type InsertIDGenerator func(event string, properties map[string]interface{}) string
+
+// WithInsertIDGenerator replaces the random $insert_id given to events which do not have one.
+func WithInsertIDGenerator(generator InsertIDGenerator) Option {
+	return func(m *MixPanel) {
+		m.insertID = generator
+	}
+}
+
+// RandomInsertID is the default InsertIDGenerator, it returns 32 random hex characters.
+func RandomInsertID(event string, properties map[string]interface{}) string {
+	var id = make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// InsertIDFromKeys derives an $insert_id from business keys such as an order number,
+// so tracking the same keys again is recognised by mixpanel as a duplicate.
+func InsertIDFromKeys(keys ...string) string {
+	var sum = sha256.Sum256([]byte(strings.Join(keys, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// InsertIDFromProperties returns an InsertIDGenerator which derives the $insert_id from the event name and
+// the values of the named properties. Events missing any of the properties are given a random $insert_id.
+func InsertIDFromProperties(names ...string) InsertIDGenerator {
+	return func(event string, properties map[string]interface{}) string {
+		var keys = []string{event}
+		for _, name := range names {
+			value, ok := properties[name]
+			if !ok {
+				return RandomInsertID(event, properties)
+			}
+			keys = append(keys, fmt.Sprint(value))
+		}
+		return InsertIDFromKeys(keys...)
+	}
+}
+
+// ensureInsertID gives the event properties an $insert_id when they do not have one.
+func (m *MixPanel) ensureInsertID(event string, properties map[string]interface{}) {
+	if _, ok := properties["$insert_id"]; ok {
+		return
+	}
+	var generator = m.insertID
+	if generator == nil {
+		generator = RandomInsertID
+	}
+	if id := generator(event, properties); id != "" {
+		properties["$insert_id"] = id
+	}
+}
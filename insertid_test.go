@@ -0,0 +1,54 @@
+package mixpanel
+
+import "testing"
+
+func TestInsertIDByDefault(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	for i := 0; i < 2; i++ {
+		if err := mixpanel.TrackEventOnly("Test InsertID"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var first = consumer.payloads[0]["properties"].(map[string]interface{})["$insert_id"]
+	var second = consumer.payloads[1]["properties"].(map[string]interface{})["$insert_id"]
+	if first == nil || first == second {
+		t.Error("Expected unique insert ids, got", first, second)
+	}
+}
+
+func TestInsertIDFromKeys(t *testing.T) {
+	var id = InsertIDFromKeys("order", "1001")
+	if len(id) != 32 {
+		t.Error("Unexpected insert id length", id)
+	}
+	if id != InsertIDFromKeys("order", "1001") {
+		t.Error("Insert ids must be deterministic")
+	}
+	if id == InsertIDFromKeys("order1", "001") {
+		t.Error("Keys must not run together")
+	}
+}
+
+func TestInsertIDFromProperties(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer), WithInsertIDGenerator(InsertIDFromProperties("order_id")))
+	var events = []Event{
+		{Name: "Purchase", Properties: map[string]interface{}{"order_id": 1001}},
+		{Name: "Purchase", Properties: map[string]interface{}{"order_id": 1001}},
+		{Name: "Purchase", Properties: map[string]interface{}{"amount": 5}},
+	}
+	if err := mixpanel.TrackEvents(events); err != nil {
+		t.Fatal(err)
+	}
+	var ids = make([]interface{}, len(consumer.payloads))
+	for i, payload := range consumer.payloads {
+		ids[i] = payload["properties"].(map[string]interface{})["$insert_id"]
+	}
+	if ids[0] != InsertIDFromKeys("Purchase", "1001") || ids[0] != ids[1] {
+		t.Error("Expected ids derived from order_id, got", ids)
+	}
+	if ids[2] == nil || ids[2] == ids[0] {
+		t.Error("Expected a random id for the event without order_id, got", ids[2])
+	}
+}
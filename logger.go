@@ -0,0 +1,61 @@
+package mixpanel
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Logger receives messages from the client as a message followed by alternating keys and values.
+// *slog.Logger satisfies it, StdLogger adapts a *log.Logger.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// WithLogger sends failures to logger. Without it the client logs nothing.
+func WithLogger(logger Logger) Option {
+	return func(m *MixPanel) {
+		m.logger = logger
+	}
+}
+
+// WithDebug logs every payload at debug level before it is sent.
+// Payloads contain the tracked properties, such as emails and phone numbers, so only enable it when needed.
+func WithDebug() Option {
+	return func(m *MixPanel) {
+		m.debug = true
+	}
+}
+
+// StdLogger adapts a *log.Logger to Logger, printing the level, message and key=value pairs on one line.
+type StdLogger struct {
+	Logger *log.Logger
+}
+
+// Debug prints msg at debug level.
+func (l StdLogger) Debug(msg string, args ...interface{}) {
+	l.print("DEBUG", msg, args)
+}
+
+// Error prints msg at error level.
+func (l StdLogger) Error(msg string, args ...interface{}) {
+	l.print("ERROR", msg, args)
+}
+
+func (l StdLogger) print(level string, msg string, args []interface{}) {
+	var line strings.Builder
+	line.WriteString(level + " " + msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			fmt.Fprintf(&line, " %v=%v", args[i], args[i+1])
+		} else {
+			fmt.Fprintf(&line, " %v", args[i])
+		}
+	}
+	var logger = l.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Print(line.String())
+}
@@ -0,0 +1,74 @@
+package mixpanel
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+)
+
+// recordingLogger keeps every message it receives.
+type recordingLogger struct {
+	debug  []string
+	errors []string
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) {
+	l.debug = append(l.debug, fmt.Sprint(msg, args))
+}
+
+func (l *recordingLogger) Error(msg string, args ...interface{}) {
+	l.errors = append(l.errors, fmt.Sprint(msg, args))
+}
+
+func TestSilentByDefault(t *testing.T) {
+	var buffer bytes.Buffer
+	var output = log.Writer()
+	log.SetOutput(&buffer)
+	defer log.SetOutput(output)
+	var server = newTestServer(t, "0")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	if err := mixpanel.ProfileSet("User 0001", map[string]interface{}{"$email": "someone@someplace.com"}); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if buffer.Len() != 0 {
+		t.Error("Expected no log output, got", buffer.String())
+	}
+}
+
+func TestLoggerErrors(t *testing.T) {
+	var server = newTestServer(t, "0")
+	var logger = &recordingLogger{}
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithLogger(logger))
+	if err := mixpanel.ProfileSet("User 0001", map[string]interface{}{"$email": "someone@someplace.com"}); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if len(logger.errors) != 1 || len(logger.debug) != 0 {
+		t.Fatal("Unexpected log messages", logger.errors, logger.debug)
+	}
+	if strings.Contains(logger.errors[0], "someone@someplace.com") {
+		t.Error("Payloads must not be logged outside debug mode", logger.errors[0])
+	}
+}
+
+func TestLoggerDebug(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var logger = &recordingLogger{}
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithLogger(logger), WithDebug())
+	if err := mixpanel.ProfileSet("User 0001", map[string]interface{}{"$email": "someone@someplace.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(logger.debug) != 1 || !strings.Contains(logger.debug[0], "someone@someplace.com") {
+		t.Error("Expected the payload to be logged in debug mode", logger.debug)
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	var logger = StdLogger{Logger: log.New(&buffer, "", 0)}
+	logger.Error("Mixpanel request failed", "endpoint", EndpointTrack, "count", 2)
+	if buffer.String() != "ERROR Mixpanel request failed endpoint=track count=2\n" {
+		t.Error("Unexpected output", buffer.String())
+	}
+}
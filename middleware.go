@@ -0,0 +1,191 @@
+package mixpanel
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MiddlewareOptions configures the middleware returned by MixPanel.Middleware.
+type MiddlewareOptions struct {
+	// EventName is the name of the event tracked for each request, "HTTP Request" by default.
+	EventName string
+	// DistinctID returns the user making the request, requests are tracked without a user when it is nil or returns "".
+	DistinctID func(r *http.Request) string
+	// Route returns the route property, the URL path by default. Return a pattern such as "/users/{id}" to keep reports readable.
+	Route func(r *http.Request) string
+	// ParseUserAgent adds the $browser, $browser_version, $os and $device properties parsed from the User-Agent header.
+	ParseUserAgent bool
+	// TrustedProxies lists the ip addresses and CIDR ranges of proxies whose X-Forwarded-For and X-Real-IP headers are believed.
+	// Headers are ignored when the request does not come from one of them.
+	TrustedProxies []string
+}
+
+// Middleware returns net/http middleware which tracks an event for every request with the method, route, status,
+// latency in milliseconds and user agent, and the client ip address. The request context carries a Scope for the
+// user, handlers can retrieve it with ScopeFromContext. An error is returned when a trusted proxy cannot be parsed.
+// Tracking happens after the handler returns, use WithAsync to keep it off the response path.
+func (m *MixPanel) Middleware(options MiddlewareOptions) (func(http.Handler) http.Handler, error) {
+	var trusted, err = parseTrustedProxies(options.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	var eventName = options.EventName
+	if eventName == "" {
+		eventName = "HTTP Request"
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var start = time.Now()
+			var distinctID = ""
+			if options.DistinctID != nil {
+				distinctID = options.DistinctID(r)
+			}
+			var scope = m.Scope(distinctID, clientIP(r, trusted), nil)
+			var recorder = &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(ContextWithScope(r.Context(), scope)))
+			var route = r.URL.Path
+			if options.Route != nil {
+				route = options.Route(r)
+			}
+			var event = NewEvent(eventName).
+				Prop("method", r.Method).
+				Prop("route", route).
+				Prop("status", recorder.status).
+				Prop("latency_ms", time.Since(start).Milliseconds()).
+				Prop("user_agent", r.UserAgent())
+			if options.ParseUserAgent {
+				event.Enrich(UserAgent(r.UserAgent()))
+			}
+			// the request context is cancelled once the client goes away, the event should still be sent
+			scope.TrackContext(context.Background(), event)
+		})
+	}, nil
+}
+
+// MustMiddleware is like Middleware but panics when a trusted proxy cannot be parsed.
+// It is meant for options fixed in the program, where an invalid proxy is a programming error.
+func (m *MixPanel) MustMiddleware(options MiddlewareOptions) func(http.Handler) http.Handler {
+	var middleware, err = m.Middleware(options)
+	if err != nil {
+		panic(err)
+	}
+	return middleware
+}
+
+// ClientIP returns the ip address of the client which made the request. X-Forwarded-For and X-Real-IP are only
+// believed when the request comes from one of the trusted proxies, given as ip addresses or CIDR ranges.
+// An error is returned when a trusted proxy cannot be parsed, as Middleware does for the same input.
+func ClientIP(r *http.Request, trustedProxies ...string) (string, error) {
+	var trusted, err = parseTrustedProxies(trustedProxies)
+	if err != nil {
+		return "", err
+	}
+	return clientIP(r, trusted), nil
+}
+
+// clientIP walks X-Forwarded-For from the right, skipping trusted proxies, to find the client address.
+func clientIP(r *http.Request, trusted []*net.IPNet) string {
+	var remote = r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if !isTrusted(remote, trusted) {
+		return remote
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		var hops = strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			var hop = strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			if !isTrusted(hop, trusted) || i == 0 {
+				return hop
+			}
+		}
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return remote
+}
+
+// parseTrustedProxies parses ip addresses and CIDR ranges.
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var trusted []*net.IPNet
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, err
+		}
+		trusted = append(trusted, network)
+	}
+	return trusted, nil
+}
+
+// isTrusted reports whether the address is in one of the trusted networks.
+func isTrusted(address string, trusted []*net.IPNet) bool {
+	var ip = net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusRecorder remembers the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(data)
+}
+
+// Flush sends buffered data to the client when the original ResponseWriter supports it, for streaming responses.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		r.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+// Hijack hands over the connection when the original ResponseWriter supports it, for websocket upgrades.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, fmt.Errorf("%T does not support hijacking", r.ResponseWriter)
+}
+
+// Unwrap lets http.ResponseController reach the original ResponseWriter.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,190 @@
+package mixpanel
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	var middleware, err = mixpanel.Middleware(MiddlewareOptions{
+		DistinctID: func(r *http.Request) string { return r.Header.Get("X-User") },
+		Route:      func(r *http.Request) string { return "/users/{id}" },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var handler = middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if scope, ok := ScopeFromContext(r.Context()); !ok || scope.DistinctID() != "User 0001" {
+			t.Error("Expected a scope for the user in the request context")
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	var request = httptest.NewRequest(http.MethodPost, "/users/42", nil)
+	request.RemoteAddr = "64.2.4.1:1234"
+	request.Header.Set("X-User", "User 0001")
+	request.Header.Set("User-Agent", "test-agent")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	if len(consumer.payloads) != 1 {
+		t.Fatal("Expected one event, got", len(consumer.payloads))
+	}
+	var payload = consumer.payloads[0]
+	var properties = payload["properties"].(map[string]interface{})
+	var expected = map[string]interface{}{
+		"distinct_id": "User 0001",
+		"ip":          "64.2.4.1",
+		"method":      http.MethodPost,
+		"route":       "/users/{id}",
+		"status":      http.StatusCreated,
+		"user_agent":  "test-agent",
+	}
+	if payload["event"] != "HTTP Request" {
+		t.Error("Unexpected event name", payload["event"])
+	}
+	for key, value := range expected {
+		if properties[key] != value {
+			t.Error("Property", key, "expected", value, "got", properties[key])
+		}
+	}
+	if _, ok := properties["latency_ms"].(int64); !ok {
+		t.Error("Expected latency_ms", properties["latency_ms"])
+	}
+}
+
+func TestMiddlewareDefaults(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	var handler = mixpanel.MustMiddleware(MiddlewareOptions{EventName: "Request"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	var properties = consumer.payloads[0]["properties"].(map[string]interface{})
+	if consumer.payloads[0]["event"] != "Request" || properties["route"] != "/health" || properties["status"] != http.StatusOK {
+		t.Error("Unexpected payload", consumer.payloads[0])
+	}
+}
+
+func TestMiddlewareInvalidProxy(t *testing.T) {
+	if _, err := NewMixPanel("token").Middleware(MiddlewareOptions{TrustedProxies: []string{"not an ip"}}); err == nil {
+		t.Error("Expected an error for an invalid trusted proxy")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for an invalid trusted proxy")
+		}
+	}()
+	NewMixPanel("token").MustMiddleware(MiddlewareOptions{TrustedProxies: []string{"not an ip"}})
+}
+
+func TestClientIP(t *testing.T) {
+	var cases = []struct {
+		remote    string
+		forwarded string
+		realIP    string
+		trusted   []string
+		expected  string
+	}{
+		{"64.2.4.1:1234", "", "", nil, "64.2.4.1"},
+		{"64.2.4.1:1234", "1.2.3.4", "5.6.7.8", nil, "64.2.4.1"},
+		{"10.0.0.1:1234", "1.2.3.4, 10.0.0.2", "", []string{"10.0.0.0/8"}, "1.2.3.4"},
+		{"10.0.0.1:1234", "6.6.6.6, 1.2.3.4", "", []string{"10.0.0.0/8"}, "1.2.3.4"},
+		{"10.0.0.1:1234", "10.0.0.3, 10.0.0.2", "", []string{"10.0.0.0/8"}, "10.0.0.3"},
+		{"10.0.0.1:1234", "", "5.6.7.8", []string{"10.0.0.1"}, "5.6.7.8"},
+		{"[::1]:1234", "2001:db8::1", "", []string{"::1"}, "2001:db8::1"},
+	}
+	for _, c := range cases {
+		var request = httptest.NewRequest(http.MethodGet, "/", nil)
+		request.RemoteAddr = c.remote
+		if c.forwarded != "" {
+			request.Header.Set("X-Forwarded-For", c.forwarded)
+		}
+		if c.realIP != "" {
+			request.Header.Set("X-Real-IP", c.realIP)
+		}
+		ip, err := ClientIP(request, c.trusted...)
+		if err != nil || ip != c.expected {
+			t.Error("Remote", c.remote, "forwarded", c.forwarded, "expected", c.expected, "got", ip, err)
+		}
+	}
+}
+
+func TestClientIPInvalidProxy(t *testing.T) {
+	var request = httptest.NewRequest(http.MethodGet, "/", nil)
+	request.RemoteAddr = "10.0.0.1:1234"
+	request.Header.Set("X-Forwarded-For", "1.2.3.4")
+	if ip, err := ClientIP(request, "10.0.0.1", "10.0.0.0/33"); err == nil {
+		t.Error("Expected an error for an invalid trusted proxy, got", ip)
+	}
+}
+
+func TestMiddlewareParseUserAgent(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	var handler = mixpanel.MustMiddleware(MiddlewareOptions{ParseUserAgent: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	var request = httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("User-Agent", "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	var properties = consumer.payloads[0]["properties"].(map[string]interface{})
+	if properties["$browser"] != "Firefox" || properties["$os"] != "Linux" {
+		t.Error("Expected the user agent properties, got", properties)
+	}
+}
+
+// hijackableRecorder is a ResponseRecorder which can also be hijacked.
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (r *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+	return nil, nil, nil
+}
+
+func TestMiddlewareFlushHijack(t *testing.T) {
+	var mixpanel = NewMixPanel("token", WithConsumer(NoopConsumer{}))
+	var handler = mixpanel.MustMiddleware(MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("Expected the ResponseWriter to be a http.Flusher")
+		}
+		flusher.Flush()
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			t.Fatal("Expected the ResponseWriter to be a http.Hijacker")
+		}
+		if _, _, err := hijacker.Hijack(); err != nil {
+			t.Error(err)
+		}
+	}))
+	var recorder = &hijackableRecorder{ResponseRecorder: httptest.NewRecorder()}
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/events", nil))
+	if !recorder.Flushed || !recorder.hijacked {
+		t.Error("Expected the original ResponseWriter to be flushed and hijacked", recorder.Flushed, recorder.hijacked)
+	}
+}
+
+func TestMiddlewareHijackUnsupported(t *testing.T) {
+	var mixpanel = NewMixPanel("token", WithConsumer(NoopConsumer{}))
+	var handler = mixpanel.MustMiddleware(MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := w.(http.Hijacker).Hijack(); err == nil {
+			t.Error("Expected an error hijacking a ResponseWriter which does not support it")
+		}
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestMiddlewareAnonymous(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	var handler = mixpanel.MustMiddleware(MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	var properties = consumer.payloads[0]["properties"].(map[string]interface{})
+	if distinctID, ok := properties["distinct_id"]; ok {
+		t.Error("Expected no distinct_id for an anonymous request, got", distinctID)
+	}
+}
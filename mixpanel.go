@@ -2,53 +2,114 @@
 package mixpanel
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
 const (
-	trackURL  string = "http://api.mixpanel.com/track/"
-	engageURL string = "http://api.mixpanel.com/engage/"
+	trackPath  string = "/track/"
+	engagePath string = "/engage/"
+	groupsPath string = "/groups/"
+	importPath string = "/import"
 )
 
 // MixPanel represents a client interface to the MixPanel HTTP interface
 type MixPanel struct {
-	Token string
+	Token    string
+	scheme   string
+	region   Region
+	baseURL  string
+	client   Doer
+	useGET   bool
+	consumer Consumer
+	retry    *RetryPolicy
+	insertID InsertIDGenerator
+	secret   string
+	account  string
+	project  string
+	verbose  bool
+	logger   Logger
+	debug    bool
+
+	superMutex      sync.RWMutex
+	superProperties map[string]interface{}
 }
 
 // NewMixPanel creates a new MixPanel.
-func NewMixPanel(token string) *MixPanel {
-	return &MixPanel{Token: token}
+// By default requests go to the US data centre over HTTPS, see Option for alternatives.
+func NewMixPanel(token string, options ...Option) *MixPanel {
+	var m = &MixPanel{Token: token}
+	for _, option := range options {
+		option(m)
+	}
+	return m
 }
 
 // NewMixPanelFromEnv creates a new MixPanel using a token from the environment.
-func NewMixPanelFromEnv(env string) *MixPanel {
-	return NewMixPanel(os.Getenv(env))
+func NewMixPanelFromEnv(env string, options ...Option) *MixPanel {
+	return NewMixPanel(os.Getenv(env), options...)
 }
 
-func (m *MixPanel) event(data map[string]interface{}) error {
-	if err := m.handleHTTPCall(data, trackURL); err != nil {
-		fmt.Print(err, data)
-		return err
+// endpoint returns the full URL for the given API path.
+func (m *MixPanel) endpoint(path string) string {
+	if m.baseURL != "" {
+		return m.baseURL + path
 	}
-	return nil
+	var scheme = m.scheme
+	if scheme == "" {
+		scheme = defaultScheme
+	}
+	return scheme + "://" + m.region.host() + path
 }
 
-func (m *MixPanel) profile(data map[string]interface{}) error {
-	if err := m.handleHTTPCall(data, engageURL); err != nil {
-		fmt.Print(err, data)
-		return err
+// httpClient returns the Doer used for all requests.
+func (m *MixPanel) httpClient() Doer {
+	if m.client == nil {
+		return defaultClient
 	}
-	return nil
+	return m.client
+}
+
+// consume hands the payloads to the configured consumer, or sends them directly when there is none.
+// Failures are logged without the payloads, which are only logged in debug mode.
+func (m *MixPanel) consume(ctx context.Context, endpoint Endpoint, payloads []map[string]interface{}) error {
+	if m.logger != nil && m.debug {
+		m.logger.Debug("Sending mixpanel payloads", "endpoint", endpoint, "payloads", payloads)
+	}
+	var consumer = m.consumer
+	if consumer == nil {
+		consumer = m.HTTPConsumer()
+	}
+	var err = consumer.Consume(ctx, endpoint, payloads)
+	if err != nil && m.logger != nil {
+		m.logger.Error("Mixpanel request failed", "endpoint", endpoint, "count", len(payloads), "error", err)
+	}
+	return err
+}
+
+func (m *MixPanel) event(ctx context.Context, data map[string]interface{}) error {
+	return m.consume(ctx, EndpointTrack, []map[string]interface{}{data})
+}
+
+func (m *MixPanel) profile(ctx context.Context, data map[string]interface{}) error {
+	return m.consume(ctx, EndpointEngage, []map[string]interface{}{data})
 }
 
-func (m *MixPanel) handleHTTPCall(data map[string]interface{}, url string) error {
+func (m *MixPanel) group(ctx context.Context, data map[string]interface{}) error {
+	return m.consume(ctx, EndpointGroups, []map[string]interface{}{data})
+}
+
+func (m *MixPanel) handleHTTPCall(ctx context.Context, data interface{}, endpointURL string) error {
 	// convert to JSON
 	jsonBytes, err := json.Marshal(data)
 	if err != nil {
@@ -57,9 +118,20 @@ func (m *MixPanel) handleHTTPCall(data map[string]interface{}, url string) error
 	// fmt.Println(string(jsonBytes))
 	// convert to base64
 	base64String := base64.StdEncoding.EncodeToString(jsonBytes)
-	// make http call
-	requestURL := url + "?data=" + base64String
-	response, err := http.Get(requestURL)
+	// make http call, retrying with the same payload when a retry policy is set
+	var form = url.Values{"data": {base64String}}
+	return m.withRetries(ctx, duplicatesSafe(data), func() error {
+		return m.doHTTPCall(ctx, endpointURL, form)
+	})
+}
+
+// doHTTPCall makes a single request and checks the response.
+func (m *MixPanel) doHTTPCall(ctx context.Context, endpointURL string, form url.Values) error {
+	request, err := m.newRequest(ctx, endpointURL, form)
+	if err != nil {
+		return err
+	}
+	response, err := m.httpClient().Do(request)
 	if err != nil {
 		return err
 	}
@@ -68,12 +140,47 @@ func (m *MixPanel) handleHTTPCall(data map[string]interface{}, url string) error
 	if bodyErr != nil {
 		return bodyErr
 	}
-	if string(body) != "1" && string(body) != "1\n" {
-		return errors.New("Error response from mixpanel server")
+	if response.StatusCode >= http.StatusBadRequest || !m.accepted(body) {
+		return newAPIError(request, response, body)
 	}
 	return nil
 }
 
+// accepted reports whether the response body says the data was accepted.
+// Verbose responses are JSON objects with a status of 1 on success, others are just "1".
+func (m *MixPanel) accepted(body []byte) bool {
+	if m.verbose {
+		var verbose struct {
+			Status int `json:"status"`
+		}
+		return json.Unmarshal(body, &verbose) == nil && verbose.Status == 1
+	}
+	return string(body) == "1" || string(body) == "1\n"
+}
+
+// newRequest builds a POST with form as the body, or a GET with form as the query string when WithGETRequests is set.
+func (m *MixPanel) newRequest(ctx context.Context, endpointURL string, form url.Values) (*http.Request, error) {
+	var query = url.Values{}
+	if m.verbose {
+		query.Set("verbose", "1")
+	}
+	if m.useGET {
+		for key, values := range form {
+			query[key] = values
+		}
+		return http.NewRequestWithContext(ctx, http.MethodGet, endpointURL+"?"+query.Encode(), nil)
+	}
+	if len(query) > 0 {
+		endpointURL += "?" + query.Encode()
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpointURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return request, nil
+}
+
 // TrackEvent tracks the event.
 func (m *MixPanel) TrackEvent(
 	event string,
@@ -81,62 +188,97 @@ func (m *MixPanel) TrackEvent(
 	timeStamp *time.Time,
 	ipAddress *string,
 	parameters *map[string]interface{}) error {
-	var properties = map[string]interface{}{
-		"token": m.Token,
+	return m.TrackEventContext(context.Background(), event, userID, timeStamp, ipAddress, parameters)
+}
+
+// TrackEventContext is like TrackEvent but uses ctx for the outgoing request.
+func (m *MixPanel) TrackEventContext(
+	ctx context.Context,
+	event string,
+	userID *string,
+	timeStamp *time.Time,
+	ipAddress *string,
+	parameters *map[string]interface{}) error {
+	var e = NewEvent(event)
+	if parameters != nil {
+		e.Props(*parameters)
 	}
 	if userID != nil {
-		properties["distinct_id"] = *userID
+		e.DistinctID(*userID)
 	}
 	if timeStamp != nil {
-		properties["time"] = timeStamp.Unix()
+		e.Time(*timeStamp)
 	}
 	if ipAddress != nil {
-		properties["ip"] = *ipAddress
+		e.IP(*ipAddress)
 	}
-	if parameters != nil {
-		properties = mergeMapsCopy(*parameters, properties)
-	}
-	var packet = map[string]interface{}{
-		"event":      event,
-		"properties": properties,
-	}
-	return m.event(packet)
+	return m.TrackContext(ctx, e)
 }
 
 // TrackEventOnly tracks an event.
 func (m *MixPanel) TrackEventOnly(event string) error {
+	return m.TrackEventOnlyContext(context.Background(), event)
+}
+
+// TrackEventOnlyContext is like TrackEventOnly but uses ctx for the outgoing request.
+func (m *MixPanel) TrackEventOnlyContext(ctx context.Context, event string) error {
 	var now = time.Now()
-	return m.TrackEvent(event, nil, &now, nil, nil)
+	return m.TrackEventContext(ctx, event, nil, &now, nil, nil)
 }
 
 // TrackEventWithParameters tracks an event with parameters.
 func (m *MixPanel) TrackEventWithParameters(event string, parameters map[string]interface{}) error {
+	return m.TrackEventWithParametersContext(context.Background(), event, parameters)
+}
+
+// TrackEventWithParametersContext is like TrackEventWithParameters but uses ctx for the outgoing request.
+func (m *MixPanel) TrackEventWithParametersContext(ctx context.Context, event string, parameters map[string]interface{}) error {
 	var now = time.Now()
-	return m.TrackEvent(event, nil, &now, nil, &parameters)
+	return m.TrackEventContext(ctx, event, nil, &now, nil, &parameters)
 }
 
 // TrackEventForUser tracks an event for the user.
 func (m *MixPanel) TrackEventForUser(event string, userID string) error {
+	return m.TrackEventForUserContext(context.Background(), event, userID)
+}
+
+// TrackEventForUserContext is like TrackEventForUser but uses ctx for the outgoing request.
+func (m *MixPanel) TrackEventForUserContext(ctx context.Context, event string, userID string) error {
 	var now = time.Now()
-	return m.TrackEvent(event, &userID, &now, nil, nil)
+	return m.TrackEventContext(ctx, event, &userID, &now, nil, nil)
 }
 
 // TrackEventForUserWithParameters tracks an event for the user with parameters.
 func (m *MixPanel) TrackEventForUserWithParameters(event string, userID string, parameters map[string]interface{}) error {
+	return m.TrackEventForUserWithParametersContext(context.Background(), event, userID, parameters)
+}
+
+// TrackEventForUserWithParametersContext is like TrackEventForUserWithParameters but uses ctx for the outgoing request.
+func (m *MixPanel) TrackEventForUserWithParametersContext(ctx context.Context, event string, userID string, parameters map[string]interface{}) error {
 	var now = time.Now()
-	return m.TrackEvent(event, &userID, &now, nil, &parameters)
+	return m.TrackEventContext(ctx, event, &userID, &now, nil, &parameters)
 }
 
 // TrackEventForUserFromIP tracks and event from a user from an ip address.
 func (m *MixPanel) TrackEventForUserFromIP(event string, userID string, ipAddress string) error {
+	return m.TrackEventForUserFromIPContext(context.Background(), event, userID, ipAddress)
+}
+
+// TrackEventForUserFromIPContext is like TrackEventForUserFromIP but uses ctx for the outgoing request.
+func (m *MixPanel) TrackEventForUserFromIPContext(ctx context.Context, event string, userID string, ipAddress string) error {
 	var now = time.Now()
-	return m.TrackEvent(event, &userID, &now, &ipAddress, nil)
+	return m.TrackEventContext(ctx, event, &userID, &now, &ipAddress, nil)
 }
 
 // TrackEventForUserFromIPWithParameters tracks and event from a user from an ip address with parameters.
 func (m *MixPanel) TrackEventForUserFromIPWithParameters(event string, userID string, ipAddress string, parameters map[string]interface{}) error {
+	return m.TrackEventForUserFromIPWithParametersContext(context.Background(), event, userID, ipAddress, parameters)
+}
+
+// TrackEventForUserFromIPWithParametersContext is like TrackEventForUserFromIPWithParameters but uses ctx for the outgoing request.
+func (m *MixPanel) TrackEventForUserFromIPWithParametersContext(ctx context.Context, event string, userID string, ipAddress string, parameters map[string]interface{}) error {
 	var now = time.Now()
-	return m.TrackEvent(event, &userID, &now, &ipAddress, &parameters)
+	return m.TrackEventContext(ctx, event, &userID, &now, &ipAddress, &parameters)
 }
 
 // ProfileSet follows the http documentation.
@@ -144,24 +286,34 @@ func (m *MixPanel) TrackEventForUserFromIPWithParameters(event string, userID st
 // If the profile does not exist, it creates it with these properties.
 // If it does exist, it sets the properties to these values, overwriting existing values.
 func (m *MixPanel) ProfileSet(userID string, attributes map[string]interface{}) error {
+	return m.ProfileSetContext(context.Background(), userID, attributes)
+}
+
+// ProfileSetContext is like ProfileSet but uses ctx for the outgoing request.
+func (m *MixPanel) ProfileSetContext(ctx context.Context, userID string, attributes map[string]interface{}) error {
 	var properties = map[string]interface{}{
 		"$token":       m.Token,
 		"$distinct_id": userID,
 		"$set":         attributes,
 	}
-	return m.profile(properties)
+	return m.profile(ctx, properties)
 }
 
 // ProfileSetOnce follows the http documentation.
 // Works just like "$set", except it will not overwrite existing property values.
 // This is useful for properties like "First login date".
 func (m *MixPanel) ProfileSetOnce(userID string, attributes map[string]interface{}) error {
+	return m.ProfileSetOnceContext(context.Background(), userID, attributes)
+}
+
+// ProfileSetOnceContext is like ProfileSetOnce but uses ctx for the outgoing request.
+func (m *MixPanel) ProfileSetOnceContext(ctx context.Context, userID string, attributes map[string]interface{}) error {
 	var properties = map[string]interface{}{
 		"$token":       m.Token,
 		"$distinct_id": userID,
 		"$set_once":    attributes,
 	}
-	return m.profile(properties)
+	return m.profile(ctx, properties)
 }
 
 // ProfileAdd follows the http documentation.
@@ -171,36 +323,81 @@ func (m *MixPanel) ProfileSetOnce(userID string, attributes map[string]interface
 // It is possible to decrement by calling "$add" with negative values.
 // This is useful for maintaining the values of properties like "Number of Logins" or "Files Uploaded".
 func (m *MixPanel) ProfileAdd(userID string, attributes map[string]int64) error {
+	return m.ProfileAddContext(context.Background(), userID, attributes)
+}
+
+// ProfileAddContext is like ProfileAdd but uses ctx for the outgoing request.
+func (m *MixPanel) ProfileAddContext(ctx context.Context, userID string, attributes map[string]int64) error {
+	var properties = map[string]interface{}{
+		"$token":       m.Token,
+		"$distinct_id": userID,
+		"$add":         attributes,
+	}
+	return m.profile(ctx, properties)
+}
+
+// ProfileAddFloat is like ProfileAdd but takes fractional values, such as amounts of money.
+func (m *MixPanel) ProfileAddFloat(userID string, attributes map[string]float64) error {
+	return m.ProfileAddFloatContext(context.Background(), userID, attributes)
+}
+
+// ProfileAddFloatContext is like ProfileAddFloat but uses ctx for the outgoing request.
+func (m *MixPanel) ProfileAddFloatContext(ctx context.Context, userID string, attributes map[string]float64) error {
+	var properties = map[string]interface{}{
+		"$token":       m.Token,
+		"$distinct_id": userID,
+		"$add":         attributes,
+	}
+	return m.profile(ctx, properties)
+}
+
+// ProfileAddDecimal is like ProfileAdd but takes Decimal values, which are sent exactly as written.
+func (m *MixPanel) ProfileAddDecimal(userID string, attributes map[string]Decimal) error {
+	return m.ProfileAddDecimalContext(context.Background(), userID, attributes)
+}
+
+// ProfileAddDecimalContext is like ProfileAddDecimal but uses ctx for the outgoing request.
+func (m *MixPanel) ProfileAddDecimalContext(ctx context.Context, userID string, attributes map[string]Decimal) error {
 	var properties = map[string]interface{}{
 		"$token":       m.Token,
 		"$distinct_id": userID,
 		"$add":         attributes,
 	}
-	return m.profile(properties)
+	return m.profile(ctx, properties)
 }
 
 // ProfileAppend follows the http documentation.
 // Takes a JSON object containing keys and values, and appends each to a list associated with the corresponding property name.
 // $appending to a property that doesn't exist will result in assigning a list with one element to that property.
 func (m *MixPanel) ProfileAppend(userID string, attributes map[string]interface{}) error {
+	return m.ProfileAppendContext(context.Background(), userID, attributes)
+}
+
+// ProfileAppendContext is like ProfileAppend but uses ctx for the outgoing request.
+func (m *MixPanel) ProfileAppendContext(ctx context.Context, userID string, attributes map[string]interface{}) error {
 	var properties = map[string]interface{}{
 		"$token":       m.Token,
 		"$distinct_id": userID,
 		"$append":      attributes,
 	}
-	return m.profile(properties)
+	return m.profile(ctx, properties)
 }
 
 // ProfileUnion follows the http documentation.
 // Takes a JSON object containing keys and list values.
 // The list values in the request are merged with the existing list on the user profile, ignoring duplicate list values.
 func (m *MixPanel) ProfileUnion(userID string, attributes map[string]interface{}) error {
+	return m.ProfileUnionContext(context.Background(), userID, attributes)
+}
+
+// ProfileUnionContext is like ProfileUnion but uses ctx for the outgoing request.
+func (m *MixPanel) ProfileUnionContext(ctx context.Context, userID string, attributes map[string]interface{}) error {
 	var properties = map[string]interface{}{
 		"$token":       m.Token,
 		"$distinct_id": userID,
 		"$union":       attributes,
 	}
-	return m.profile(properties)
+	return m.profile(ctx, properties)
 }
 
 // ProfileRemove follows the http documentation.
@@ -209,35 +406,50 @@ func (m *MixPanel) ProfileUnion(userID string, attributes map[string]interface{}
 // The value in the request is removed from the existing list on the user profile.
 // If it does not exist, no updates are made.
 func (m *MixPanel) ProfileRemove(userID string, attributes map[string]interface{}) error {
+	return m.ProfileRemoveContext(context.Background(), userID, attributes)
+}
+
+// ProfileRemoveContext is like ProfileRemove but uses ctx for the outgoing request.
+func (m *MixPanel) ProfileRemoveContext(ctx context.Context, userID string, attributes map[string]interface{}) error {
 	var properties = map[string]interface{}{
 		"$token":       m.Token,
 		"$distinct_id": userID,
 		"$remove":      attributes,
 	}
-	return m.profile(properties)
+	return m.profile(ctx, properties)
 }
 
 // ProfileUnset follows the http documentation.
 // Takes a JSON list of string property names, and permanently removes the properties and their values from a profile.
 func (m *MixPanel) ProfileUnset(userID string, keyList []string) error {
+	return m.ProfileUnsetContext(context.Background(), userID, keyList)
+}
+
+// ProfileUnsetContext is like ProfileUnset but uses ctx for the outgoing request.
+func (m *MixPanel) ProfileUnsetContext(ctx context.Context, userID string, keyList []string) error {
 	var properties = map[string]interface{}{
 		"$token":       m.Token,
 		"$distinct_id": userID,
 		"$unset":       keyList,
 	}
-	return m.profile(properties)
+	return m.profile(ctx, properties)
 }
 
 // ProfileDelete follows the http documentation.
 // Permanently delete the profile from Mixpanel, along with all of its properties.
 // The value is ignored - the profile is determined by the $distinct_id from the request itself.
 func (m *MixPanel) ProfileDelete(userID string) error {
+	return m.ProfileDeleteContext(context.Background(), userID)
+}
+
+// ProfileDeleteContext is like ProfileDelete but uses ctx for the outgoing request.
+func (m *MixPanel) ProfileDeleteContext(ctx context.Context, userID string) error {
 	var properties = map[string]interface{}{
 		"$token":       m.Token,
 		"$distinct_id": userID,
 		"$delete":      "",
 	}
-	return m.profile(properties)
+	return m.profile(ctx, properties)
 }
 
 // CurrentTimeString returns the current time as a string format suitable for use in the mixpanel.
@@ -267,44 +479,108 @@ func mergeMapsCopy(source map[string]interface{}, overwrite map[string]interface
 
 // ProfilePropertyIncrement increments the userID's property by 1
 func (m *MixPanel) ProfilePropertyIncrement(userID string, property string) error {
-	return m.profilePropertyAdjustBy(userID, property, 1)
+	return m.ProfilePropertyIncrementContext(context.Background(), userID, property)
+}
+
+// ProfilePropertyIncrementContext is like ProfilePropertyIncrement but uses ctx for the outgoing request.
+func (m *MixPanel) ProfilePropertyIncrementContext(ctx context.Context, userID string, property string) error {
+	return m.profilePropertyAdjustBy(ctx, userID, property, 1)
 }
 
 // ProfilePropertyIncrementBy increments the userID's property by value
 // value here must be positive and cannot be zero
 func (m *MixPanel) ProfilePropertyIncrementBy(userID string, property string, value int64) error {
+	return m.ProfilePropertyIncrementByContext(context.Background(), userID, property, value)
+}
+
+// ProfilePropertyIncrementByContext is like ProfilePropertyIncrementBy but uses ctx for the outgoing request.
+func (m *MixPanel) ProfilePropertyIncrementByContext(ctx context.Context, userID string, property string, value int64) error {
 	if value <= 0 {
 		return errors.New("Value must be greater than zero")
 	}
-	return m.profilePropertyAdjustBy(userID, property, value)
+	return m.profilePropertyAdjustBy(ctx, userID, property, value)
 }
 
 // ProfilePropertyDecrement decrements the userID's property by 1
 func (m *MixPanel) ProfilePropertyDecrement(userID string, property string) error {
-	return m.profilePropertyAdjustBy(userID, property, -1)
+	return m.ProfilePropertyDecrementContext(context.Background(), userID, property)
+}
+
+// ProfilePropertyDecrementContext is like ProfilePropertyDecrement but uses ctx for the outgoing request.
+func (m *MixPanel) ProfilePropertyDecrementContext(ctx context.Context, userID string, property string) error {
+	return m.profilePropertyAdjustBy(ctx, userID, property, -1)
 }
 
 // ProfilePropertyDecrementBy decrements the userID's property by value
 // value here must be positive and cannot be zero
 func (m *MixPanel) ProfilePropertyDecrementBy(userID string, property string, value int64) error {
+	return m.ProfilePropertyDecrementByContext(context.Background(), userID, property, value)
+}
+
+// ProfilePropertyDecrementByContext is like ProfilePropertyDecrementBy but uses ctx for the outgoing request.
+func (m *MixPanel) ProfilePropertyDecrementByContext(ctx context.Context, userID string, property string, value int64) error {
 	if value <= 0 {
 		return errors.New("Value must be greater than zero")
 	}
-	return m.profilePropertyAdjustBy(userID, property, -value)
+	return m.profilePropertyAdjustBy(ctx, userID, property, -value)
+}
+
+// ProfilePropertyIncrementByFloat increments the userID's property by a fractional value
+// value here must be positive and cannot be zero
+func (m *MixPanel) ProfilePropertyIncrementByFloat(userID string, property string, value float64) error {
+	return m.ProfilePropertyIncrementByFloatContext(context.Background(), userID, property, value)
+}
+
+// ProfilePropertyIncrementByFloatContext is like ProfilePropertyIncrementByFloat but uses ctx for the outgoing request.
+func (m *MixPanel) ProfilePropertyIncrementByFloatContext(ctx context.Context, userID string, property string, value float64) error {
+	if err := checkPositiveFloat(value); err != nil {
+		return err
+	}
+	return m.ProfileAddFloatContext(ctx, userID, map[string]float64{property: value})
+}
+
+// ProfilePropertyDecrementByFloat decrements the userID's property by a fractional value
+// value here must be positive and cannot be zero
+func (m *MixPanel) ProfilePropertyDecrementByFloat(userID string, property string, value float64) error {
+	return m.ProfilePropertyDecrementByFloatContext(context.Background(), userID, property, value)
+}
+
+// ProfilePropertyDecrementByFloatContext is like ProfilePropertyDecrementByFloat but uses ctx for the outgoing request.
+func (m *MixPanel) ProfilePropertyDecrementByFloatContext(ctx context.Context, userID string, property string, value float64) error {
+	if err := checkPositiveFloat(value); err != nil {
+		return err
+	}
+	return m.ProfileAddFloatContext(ctx, userID, map[string]float64{property: -value})
+}
+
+// checkPositiveFloat rejects values which are not greater than zero, including NaN, and infinity which JSON cannot encode.
+func checkPositiveFloat(value float64) error {
+	if !(value > 0) {
+		return errors.New("Value must be greater than zero")
+	}
+	if math.IsInf(value, 1) {
+		return errors.New("Value must be finite")
+	}
+	return nil
 }
 
 // ProfilePropertyAdjustBy changes the userID's property by value
 // value here must be negative
-func (m *MixPanel) profilePropertyAdjustBy(userID string, property string, value int64) error {
+func (m *MixPanel) profilePropertyAdjustBy(ctx context.Context, userID string, property string, value int64) error {
 	var attributes = map[string]int64{
 		property: value,
 	}
-	return m.ProfileAdd(userID, attributes)
+	return m.ProfileAddContext(ctx, userID, attributes)
 }
 
 // ProfileAddRevenueTransaction adds a transaction to the mixpanel
 // Not tested yet
 func (m *MixPanel) ProfileAddRevenueTransaction(userID string, timeStamp time.Time, productCode string, amount float64) error {
+	return m.ProfileAddRevenueTransactionContext(context.Background(), userID, timeStamp, productCode, amount)
+}
+
+// ProfileAddRevenueTransactionContext is like ProfileAddRevenueTransaction but uses ctx for the outgoing request.
+func (m *MixPanel) ProfileAddRevenueTransactionContext(ctx context.Context, userID string, timeStamp time.Time, productCode string, amount float64) error {
 	var properties = map[string]interface{}{
 		"$token":       m.Token,
 		"$distinct_id": userID,
@@ -316,5 +592,5 @@ func (m *MixPanel) ProfileAddRevenueTransaction(userID string, timeStamp time.Ti
 			},
 		},
 	}
-	return m.profile(properties)
+	return m.profile(ctx, properties)
 }
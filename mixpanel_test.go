@@ -1,9 +1,17 @@
 package mixpanel
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -341,3 +349,110 @@ func TestProfileProfileAddRevenueTransaction(t *testing.T) {
 		return
 	}
 }
+
+// testServer is a local stand-in for the mixpanel API that records the requests it receives.
+type testServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	requests []*http.Request
+	payloads []interface{}
+	response string
+}
+
+// newTestServer starts a testServer which answers every request with response.
+func newTestServer(t *testing.T, response string) *testServer {
+	var server = &testServer{response: response}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload interface{}
+		if data := r.FormValue("data"); data != "" {
+			decoded, err := base64.StdEncoding.DecodeString(data)
+			if err != nil {
+				t.Error(err)
+			}
+			if err := json.Unmarshal(decoded, &payload); err != nil {
+				t.Error(err)
+			}
+		}
+		server.mutex.Lock()
+		server.requests = append(server.requests, r)
+		server.payloads = append(server.payloads, payload)
+		server.mutex.Unlock()
+		fmt.Fprint(w, server.response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// lastPayload returns the decoded data of the most recent request.
+func (s *testServer) lastPayload(t *testing.T) map[string]interface{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.payloads) == 0 {
+		t.Fatal("No request received")
+	}
+	payload, ok := s.payloads[len(s.payloads)-1].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Unexpected payload %v", s.payloads[len(s.payloads)-1])
+	}
+	return payload
+}
+
+func TestTrackEventContextCancelled(t *testing.T) {
+	var release = make(chan struct{})
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		fmt.Fprint(w, "1")
+	}))
+	defer server.Close()
+	defer close(release)
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var err = mixpanel.TrackEventOnlyContext(ctx, "Test TrackEventOnlyContext")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("Expected deadline exceeded, got", err)
+	}
+}
+
+func TestProfileSetContext(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	if err := mixpanel.ProfileSetContext(context.Background(), "User 0001", map[string]interface{}{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	var payload = server.lastPayload(t)
+	if payload["$distinct_id"] != "User 0001" || payload["$token"] != "token" {
+		t.Error("Unexpected payload", payload)
+	}
+}
+
+func TestProfileAddFloat(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	if err := mixpanel.ProfilePropertyIncrementByFloat("User 0001", "lifetime_spend", 19.99); err != nil {
+		t.Fatal(err)
+	}
+	var add = server.lastPayload(t)["$add"].(map[string]interface{})
+	if add["lifetime_spend"] != 19.99 {
+		t.Error("Unexpected $add", add)
+	}
+	if err := mixpanel.ProfilePropertyDecrementByFloat("User 0001", "balance", 0.5); err != nil {
+		t.Fatal(err)
+	}
+	add = server.lastPayload(t)["$add"].(map[string]interface{})
+	if add["balance"] != -0.5 {
+		t.Error("Unexpected $add", add)
+	}
+}
+
+func TestProfilePropertyAdjustByFloatValidation(t *testing.T) {
+	var mixpanel = NewMixPanel("token", WithConsumer(NoopConsumer{}))
+	for _, value := range []float64{0, -1.5, math.NaN(), math.Inf(1)} {
+		if err := mixpanel.ProfilePropertyIncrementByFloat("User 0001", "lifetime_spend", value); err == nil {
+			t.Error("Expected an error incrementing by", value)
+		}
+		if err := mixpanel.ProfilePropertyDecrementByFloat("User 0001", "lifetime_spend", value); err == nil {
+			t.Error("Expected an error decrementing by", value)
+		}
+	}
+}
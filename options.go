@@ -0,0 +1,91 @@
+package mixpanel
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultScheme string = "https"
+
+// defaultClient is used when no Doer is given, the timeout stops a hung server from blocking callers forever.
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+// Doer sends an HTTP request and returns the response, *http.Client satisfies it.
+type Doer interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// Region selects the Mixpanel data residency region the client talks to.
+type Region int
+
+const (
+	// RegionUS is the default region, api.mixpanel.com.
+	RegionUS Region = iota
+	// RegionEU sends data to the EU residency servers, api-eu.mixpanel.com.
+	RegionEU
+	// RegionIN sends data to the India residency servers, api-in.mixpanel.com.
+	RegionIN
+)
+
+// host returns the API host name for the region.
+func (r Region) host() string {
+	switch r {
+	case RegionEU:
+		return "api-eu.mixpanel.com"
+	case RegionIN:
+		return "api-in.mixpanel.com"
+	default:
+		return "api.mixpanel.com"
+	}
+}
+
+// Option configures a MixPanel when passed to NewMixPanel or NewMixPanelFromEnv.
+type Option func(*MixPanel)
+
+// WithRegion sends all requests to the servers of the given residency region.
+func WithRegion(region Region) Option {
+	return func(m *MixPanel) {
+		m.region = region
+	}
+}
+
+// WithScheme changes the URL scheme used to reach the region host.
+// The default is "https", use "http" only when a plain connection is required.
+func WithScheme(scheme string) Option {
+	return func(m *MixPanel) {
+		m.scheme = scheme
+	}
+}
+
+// WithBaseURL sends all requests to a custom base URL such as "https://proxy.example.com" or a local test server.
+// It takes precedence over WithRegion and WithScheme.
+func WithBaseURL(baseURL string) Option {
+	return func(m *MixPanel) {
+		m.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sends every request through client instead of the default client.
+// Use it to set timeouts, proxies, TLS configuration, connection pooling or a test transport.
+func WithHTTPClient(client Doer) Option {
+	return func(m *MixPanel) {
+		m.client = client
+	}
+}
+
+// WithGETRequests sends payloads base64 encoded in the query string of a GET request, as older versions of this package did.
+// By default payloads are sent as the form encoded body of a POST request, which avoids URL length limits and keeps data out of access logs.
+func WithGETRequests() Option {
+	return func(m *MixPanel) {
+		m.useGET = true
+	}
+}
+
+// WithVerbose asks mixpanel for verbose responses on /track and /engage.
+// They explain why data was rejected, the reason is returned in the Message of the APIError.
+func WithVerbose() Option {
+	return func(m *MixPanel) {
+		m.verbose = true
+	}
+}
@@ -0,0 +1,173 @@
+package mixpanel
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestEndpointDefaults(t *testing.T) {
+	var mixpanel = NewMixPanel(ValidTestToken)
+	if url := mixpanel.endpoint(trackPath); url != "https://api.mixpanel.com/track/" {
+		t.Error("Unexpected default endpoint", url)
+	}
+}
+
+func TestEndpointRegions(t *testing.T) {
+	var cases = map[Region]string{
+		RegionUS: "https://api.mixpanel.com/engage/",
+		RegionEU: "https://api-eu.mixpanel.com/engage/",
+		RegionIN: "https://api-in.mixpanel.com/engage/",
+	}
+	for region, expected := range cases {
+		var mixpanel = NewMixPanel(ValidTestToken, WithRegion(region))
+		if url := mixpanel.endpoint(engagePath); url != expected {
+			t.Error("Unexpected endpoint", url, "expected", expected)
+		}
+	}
+}
+
+func TestEndpointScheme(t *testing.T) {
+	var mixpanel = NewMixPanel(ValidTestToken, WithRegion(RegionEU), WithScheme("http"))
+	if url := mixpanel.endpoint(trackPath); url != "http://api-eu.mixpanel.com/track/" {
+		t.Error("Unexpected endpoint", url)
+	}
+}
+
+func TestEndpointBaseURL(t *testing.T) {
+	var mixpanel = NewMixPanel(ValidTestToken, WithRegion(RegionIN), WithBaseURL("http://localhost:8080/"))
+	if url := mixpanel.endpoint(trackPath); url != "http://localhost:8080/track/" {
+		t.Error("Unexpected endpoint", url)
+	}
+}
+
+func TestBaseURLServer(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	if err := mixpanel.TrackEventForUser("Test BaseURL", "User 0001"); err != nil {
+		t.Fatal(err)
+	}
+	var payload = server.lastPayload(t)
+	if payload["event"] != "Test BaseURL" {
+		t.Error("Unexpected event", payload["event"])
+	}
+	if server.requests[0].URL.Path != trackPath {
+		t.Error("Unexpected path", server.requests[0].URL.Path)
+	}
+}
+
+// roundTripFunc lets a function act as an http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	var calls = 0
+	var client = &http.Client{Transport: roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		calls++
+		if request.URL.Host != "api-eu.mixpanel.com" {
+			t.Error("Unexpected host", request.URL.Host)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("1")),
+			Header:     make(http.Header),
+		}, nil
+	})}
+	var mixpanel = NewMixPanel("token", WithRegion(RegionEU), WithHTTPClient(client))
+	if err := mixpanel.TrackEventOnly("Test WithHTTPClient"); err != nil {
+		t.Error(err)
+	}
+	if err := mixpanel.ProfileSet("User 0001", map[string]interface{}{"a": 1}); err != nil {
+		t.Error(err)
+	}
+	if calls != 2 {
+		t.Error("Expected 2 calls through the client, got", calls)
+	}
+}
+
+func TestDefaultClientTimeout(t *testing.T) {
+	if defaultClient.Timeout <= 0 {
+		t.Error("Default client must have a timeout")
+	}
+}
+
+func TestPostFormByDefault(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	if err := mixpanel.TrackEventOnly("Test POST"); err != nil {
+		t.Fatal(err)
+	}
+	var request = server.requests[0]
+	if request.Method != http.MethodPost {
+		t.Error("Unexpected method", request.Method)
+	}
+	if request.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		t.Error("Unexpected content type", request.Header.Get("Content-Type"))
+	}
+	if request.URL.RawQuery != "" {
+		t.Error("Unexpected query string", request.URL.RawQuery)
+	}
+	if server.lastPayload(t)["event"] != "Test POST" {
+		t.Error("Unexpected payload", server.lastPayload(t))
+	}
+}
+
+func TestWithGETRequests(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithGETRequests())
+	if err := mixpanel.TrackEventOnly("Test GET"); err != nil {
+		t.Fatal(err)
+	}
+	var request = server.requests[0]
+	if request.Method != http.MethodGet {
+		t.Error("Unexpected method", request.Method)
+	}
+	if request.URL.Query().Get("data") == "" {
+		t.Error("Missing data in query string")
+	}
+	if server.lastPayload(t)["event"] != "Test GET" {
+		t.Error("Unexpected payload", server.lastPayload(t))
+	}
+}
+
+func TestWithVerbose(t *testing.T) {
+	var server = newTestServer(t, `{"status":1,"error":null}`)
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithVerbose())
+	if err := mixpanel.TrackEventOnly("Test Verbose"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mixpanel.ProfileDelete("User 0001"); err != nil {
+		t.Fatal(err)
+	}
+	for _, request := range server.requests {
+		if request.URL.Query().Get("verbose") != "1" {
+			t.Error("Expected verbose=1 in", request.URL)
+		}
+	}
+	mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithVerbose(), WithGETRequests())
+	if err := mixpanel.TrackEventOnly("Test Verbose"); err != nil {
+		t.Fatal(err)
+	}
+	var query = server.requests[2].URL.Query()
+	if query.Get("verbose") != "1" || query.Get("data") == "" {
+		t.Error("Unexpected query", query)
+	}
+}
+
+func TestWithVerboseError(t *testing.T) {
+	var server = newTestServer(t, `{"status":0,"error":"'event' must be a non-empty string"}`)
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithVerbose())
+	var err = mixpanel.TrackEventOnly("")
+	var apiError *APIError
+	if !errors.As(err, &apiError) || apiError.Message != "'event' must be a non-empty string" {
+		t.Fatal("Expected the verbose error message, got", err)
+	}
+	if !strings.Contains(err.Error(), apiError.Message) {
+		t.Error("Expected the message in the error text, got", err)
+	}
+}
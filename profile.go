@@ -0,0 +1,146 @@
+package mixpanel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ProfileUpdate collects several profile operators for one user so they can be sent in a single request.
+// Values are checked as they are added, the first problem is returned by Operations and UpdateProfile.
+type ProfileUpdate struct {
+	distinctID string
+	values     map[string]map[string]interface{}
+	unset      []string
+	err        error
+}
+
+// profileOperatorOrder is the order operators are sent in, so that for example $set happens before $unset.
+var profileOperatorOrder = []string{OperatorSet, OperatorSetOnce, OperatorAdd, OperatorAppend, OperatorUnion, OperatorRemove}
+
+// NewProfileUpdate creates an empty update for the user.
+func NewProfileUpdate(distinctID string) *ProfileUpdate {
+	return &ProfileUpdate{distinctID: distinctID, values: make(map[string]map[string]interface{})}
+}
+
+// Set overwrites the property, see ProfileSet.
+func (u *ProfileUpdate) Set(property string, value interface{}) *ProfileUpdate {
+	return u.put(OperatorSet, property, value)
+}
+
+// SetOnce sets the property unless it already has a value, see ProfileSetOnce.
+func (u *ProfileUpdate) SetOnce(property string, value interface{}) *ProfileUpdate {
+	return u.put(OperatorSetOnce, property, value)
+}
+
+// Add adds value, which must be a number, to the property, see ProfileAdd.
+func (u *ProfileUpdate) Add(property string, value interface{}) *ProfileUpdate {
+	if !isNumeric(value) {
+		return u.fail(fmt.Errorf("Value of %s for %s must be numeric, got %T", property, OperatorAdd, value))
+	}
+	return u.put(OperatorAdd, property, value)
+}
+
+// Append appends value to the list property, see ProfileAppend.
+func (u *ProfileUpdate) Append(property string, value interface{}) *ProfileUpdate {
+	return u.put(OperatorAppend, property, value)
+}
+
+// Union merges the list values into the list property without duplicates, see ProfileUnion.
+func (u *ProfileUpdate) Union(property string, values interface{}) *ProfileUpdate {
+	if !isList(values) {
+		return u.fail(fmt.Errorf("Value of %s for %s must be a list, got %T", property, OperatorUnion, values))
+	}
+	return u.put(OperatorUnion, property, values)
+}
+
+// Remove removes value, which cannot be a list, from the list property, see ProfileRemove.
+func (u *ProfileUpdate) Remove(property string, value interface{}) *ProfileUpdate {
+	if isList(value) {
+		return u.fail(fmt.Errorf("Value of %s for %s cannot be a list", property, OperatorRemove))
+	}
+	return u.put(OperatorRemove, property, value)
+}
+
+// Unset permanently removes the properties, see ProfileUnset.
+func (u *ProfileUpdate) Unset(properties ...string) *ProfileUpdate {
+	u.unset = append(u.unset, properties...)
+	return u
+}
+
+// Operations returns one ProfileOperation for each operator used, or the first validation error.
+func (u *ProfileUpdate) Operations() ([]ProfileOperation, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+	if u.distinctID == "" {
+		return nil, errors.New("Profile update needs a distinct id")
+	}
+	var operations []ProfileOperation
+	for _, operator := range profileOperatorOrder {
+		if values, ok := u.values[operator]; ok {
+			operations = append(operations, ProfileOperation{DistinctID: u.distinctID, Operator: operator, Value: values})
+		}
+	}
+	if len(u.unset) > 0 {
+		operations = append(operations, ProfileOperation{DistinctID: u.distinctID, Operator: OperatorUnset, Value: u.unset})
+	}
+	return operations, nil
+}
+
+// UpdateProfile sends every operator of the update in a single request.
+func (m *MixPanel) UpdateProfile(update *ProfileUpdate) error {
+	return m.UpdateProfileContext(context.Background(), update)
+}
+
+// UpdateProfileContext is like UpdateProfile but uses ctx for the outgoing request.
+func (m *MixPanel) UpdateProfileContext(ctx context.Context, update *ProfileUpdate) error {
+	operations, err := update.Operations()
+	if err != nil || len(operations) == 0 {
+		return err
+	}
+	return m.ProfileBatchContext(ctx, operations)
+}
+
+// put records the value of the property for the operator.
+func (u *ProfileUpdate) put(operator string, property string, value interface{}) *ProfileUpdate {
+	if u.values == nil {
+		u.values = make(map[string]map[string]interface{})
+	}
+	if u.values[operator] == nil {
+		u.values[operator] = make(map[string]interface{})
+	}
+	u.values[operator][property] = value
+	return u
+}
+
+// fail keeps the first validation error.
+func (u *ProfileUpdate) fail(err error) *ProfileUpdate {
+	if u.err == nil {
+		u.err = err
+	}
+	return u
+}
+
+// isNumeric reports whether value is sent to mixpanel as a JSON number.
+func isNumeric(value interface{}) bool {
+	switch value.(type) {
+	case json.Number, Decimal:
+		return true
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isList reports whether value is sent to mixpanel as a JSON array.
+func isList(value interface{}) bool {
+	var kind = reflect.ValueOf(value).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
@@ -0,0 +1,59 @@
+package mixpanel
+
+import "testing"
+
+func TestUpdateProfile(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	var update = NewProfileUpdate("User 0001").
+		Set("$name", "One").
+		Add("logins", 1).
+		Add("spend", 12.5).
+		Union("tags", []string{"beta"}).
+		Unset("legacy").
+		Set("plan", "pro")
+	if err := mixpanel.UpdateProfile(update); err != nil {
+		t.Fatal(err)
+	}
+	if len(consumer.payloads) != 4 {
+		t.Fatal("Expected 4 records, got", consumer.payloads)
+	}
+	var operators = []string{OperatorSet, OperatorAdd, OperatorUnion, OperatorUnset}
+	for i, operator := range operators {
+		var record = consumer.payloads[i]
+		if _, ok := record[operator]; !ok || record["$distinct_id"] != "User 0001" || record["$token"] != "token" {
+			t.Error("Record", i, "expected", operator, "got", record)
+		}
+	}
+	var set = consumer.payloads[0][OperatorSet].(map[string]interface{})
+	if set["$name"] != "One" || set["plan"] != "pro" {
+		t.Error("Expected both $set properties in one record", set)
+	}
+}
+
+func TestUpdateProfileValidation(t *testing.T) {
+	var cases = map[string]*ProfileUpdate{
+		"non numeric $add":    NewProfileUpdate("User 0001").Add("logins", "1"),
+		"list for $remove":    NewProfileUpdate("User 0001").Remove("tags", []string{"beta"}),
+		"non list for $union": NewProfileUpdate("User 0001").Union("tags", "beta"),
+		"missing distinct id": NewProfileUpdate("").Set("$name", "One"),
+	}
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	for name, update := range cases {
+		if err := mixpanel.UpdateProfile(update); err == nil {
+			t.Error("Expected an error for", name)
+		}
+	}
+	if len(consumer.payloads) != 0 {
+		t.Error("Invalid updates must not be sent")
+	}
+}
+
+func TestUpdateProfileEmpty(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	if err := mixpanel.UpdateProfile(NewProfileUpdate("User 0001")); err != nil || len(consumer.payloads) != 0 {
+		t.Error("Expected nothing to be sent", err, consumer.payloads)
+	}
+}
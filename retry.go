@@ -0,0 +1,166 @@
+package mixpanel
+
+import (
+	"context"
+	"errors"
+	mathrand "math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryCondition is a set of failures which may be retried, combine them with |.
+type RetryCondition int
+
+const (
+	// RetryOnNetworkError retries when the request could not be made or the response could not be read.
+	// Mixpanel may already have applied such a request, so profile updates using $add or $append,
+	// which have no $insert_id to drop duplicates, are never retried on it.
+	RetryOnNetworkError RetryCondition = 1 << iota
+	// RetryOnServerError retries when mixpanel answers with a 5xx status.
+	RetryOnServerError
+	// RetryOnRateLimit retries when mixpanel answers with 429 Too Many Requests, honouring Retry-After.
+	RetryOnRateLimit
+)
+
+// RetryPolicy describes how failed requests are retried with exponential backoff.
+// Zero values are replaced by the defaults noted on each field.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first, 3 by default.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry, it doubles on every further retry. 100ms by default.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay, 10 seconds by default. A longer Retry-After is still honoured.
+	MaxDelay time.Duration
+	// Jitter is the fraction of each delay, between 0 and 1, which is randomised to spread out retries.
+	// Values outside that range are clamped to it.
+	Jitter float64
+	// RetryOn is the set of failures which are retried, all of them by default.
+	RetryOn RetryCondition
+}
+
+// WithRetry retries failed requests following policy.
+// Retries resend the same payload, so the $insert_id of each event lets mixpanel drop the duplicates.
+// Profile and group updates have no $insert_id, those using $add or $append would be applied twice if a request
+// which already reached mixpanel were resent, so they are only retried on 429 Too Many Requests.
+func WithRetry(policy RetryPolicy) Option {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = 100 * time.Millisecond
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = 10 * time.Second
+	}
+	if policy.Jitter < 0 {
+		policy.Jitter = 0
+	} else if policy.Jitter > 1 {
+		policy.Jitter = 1
+	}
+	if policy.RetryOn == 0 {
+		policy.RetryOn = RetryOnNetworkError | RetryOnServerError | RetryOnRateLimit
+	}
+	return func(m *MixPanel) {
+		m.retry = &policy
+	}
+}
+
+// withRetries calls attempt until it succeeds or the retry policy gives up.
+// When duplicates are unsafe only rate limited attempts, which mixpanel did not apply, are retried.
+func (m *MixPanel) withRetries(ctx context.Context, duplicatesSafe bool, attempt func() error) error {
+	for count := 1; ; count++ {
+		var err = attempt()
+		if err == nil || !m.retry.shouldRetry(ctx, err, count, duplicatesSafe) {
+			return err
+		}
+		if err := sleepContext(ctx, m.retry.delay(err, count)); err != nil {
+			return err
+		}
+	}
+}
+
+// shouldRetry reports whether another attempt should follow the failed attempt.
+// A nil policy never retries.
+func (p *RetryPolicy) shouldRetry(ctx context.Context, err error, attempt int, duplicatesSafe bool) bool {
+	if p == nil || attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		switch {
+		case apiError.StatusCode == http.StatusTooManyRequests:
+			return p.RetryOn&RetryOnRateLimit != 0
+		case !duplicatesSafe:
+			return false
+		case apiError.StatusCode >= http.StatusInternalServerError:
+			return p.RetryOn&RetryOnServerError != 0
+		default:
+			return false
+		}
+	}
+	return duplicatesSafe && p.RetryOn&RetryOnNetworkError != 0
+}
+
+// duplicatesSafe reports whether mixpanel can receive the /track, /engage or /groups payload twice without harm.
+// Events are deduplicated by $insert_id, profile operators are safe unless they change a value relative to
+// the current one, as $add and $append do.
+func duplicatesSafe(data interface{}) bool {
+	var records []map[string]interface{}
+	switch data := data.(type) {
+	case map[string]interface{}:
+		records = []map[string]interface{}{data}
+	case []map[string]interface{}:
+		records = data
+	}
+	for _, record := range records {
+		for _, operator := range []string{OperatorAdd, OperatorAppend} {
+			if _, ok := record[operator]; ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// delay returns the wait before the attempt following attempt.
+func (p *RetryPolicy) delay(err error, attempt int) time.Duration {
+	var delay = p.BaseDelay << uint(attempt-1)
+	if delay > p.MaxDelay || delay <= 0 {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(p.Jitter * mathrand.Float64() * float64(delay))
+	}
+	var apiError *APIError
+	if errors.As(err, &apiError) && apiError.RetryAfter > delay {
+		delay = apiError.RetryAfter
+	}
+	return delay
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+// sleepContext waits for duration or until ctx is done.
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	var timer = time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,238 @@
+package mixpanel
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedResponse is one answer of a scripted server.
+type scriptedResponse struct {
+	status     int
+	body       string
+	retryAfter string
+}
+
+// newScriptedServer answers requests with responses in order, repeating the last one, and records the data sent.
+func newScriptedServer(t *testing.T, responses ...scriptedResponse) (*httptest.Server, func() []string) {
+	var mutex sync.Mutex
+	var data []string
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		data = append(data, r.FormValue("data"))
+		var response = responses[len(responses)-1]
+		if len(data) <= len(responses) {
+			response = responses[len(data)-1]
+		}
+		mutex.Unlock()
+		if response.retryAfter != "" {
+			w.Header().Set("Retry-After", response.retryAfter)
+		}
+		w.WriteHeader(response.status)
+		w.Write([]byte(response.body))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string(nil), data...)
+	}
+}
+
+func TestRetryServerError(t *testing.T) {
+	var server, data = newScriptedServer(t,
+		scriptedResponse{status: http.StatusServiceUnavailable, body: "0"},
+		scriptedResponse{status: http.StatusBadGateway, body: "0"},
+		scriptedResponse{status: http.StatusOK, body: "1"},
+	)
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithRetry(RetryPolicy{BaseDelay: time.Millisecond}))
+	if err := mixpanel.TrackEventOnly("Test Retry"); err != nil {
+		t.Fatal(err)
+	}
+	var sent = data()
+	if len(sent) != 3 {
+		t.Fatal("Expected 3 attempts, got", len(sent))
+	}
+	if sent[0] != sent[1] || sent[1] != sent[2] {
+		t.Error("Retries must resend the same payload")
+	}
+}
+
+func TestRetryInsertID(t *testing.T) {
+	var server = newTestServer(t, "1")
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithRetry(RetryPolicy{}))
+	if err := mixpanel.TrackEventOnly("Test Retry"); err != nil {
+		t.Fatal(err)
+	}
+	var properties = server.lastPayload(t)["properties"].(map[string]interface{})
+	if id, ok := properties["$insert_id"].(string); !ok || len(id) != 32 {
+		t.Error("Expected a generated $insert_id, got", properties["$insert_id"])
+	}
+	var parameters = map[string]interface{}{"$insert_id": "order-1"}
+	if err := mixpanel.TrackEventWithParameters("Test Retry", parameters); err != nil {
+		t.Fatal(err)
+	}
+	properties = server.lastPayload(t)["properties"].(map[string]interface{})
+	if properties["$insert_id"] != "order-1" {
+		t.Error("Expected the given $insert_id to be kept, got", properties["$insert_id"])
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	var server, data = newScriptedServer(t, scriptedResponse{status: http.StatusInternalServerError, body: "0"})
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithRetry(RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond}))
+	if err := mixpanel.TrackEventOnly("Test Retry"); err == nil {
+		t.Error("Expected an error")
+	}
+	if len(data()) != 4 {
+		t.Error("Expected 4 attempts, got", len(data()))
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	var server, data = newScriptedServer(t, scriptedResponse{status: http.StatusOK, body: "0"})
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithRetry(RetryPolicy{BaseDelay: time.Millisecond}))
+	if err := mixpanel.TrackEventOnly("Test Retry"); err == nil {
+		t.Error("Expected an error")
+	}
+	if len(data()) != 1 {
+		t.Error("Rejected payloads must not be retried, got", len(data()), "attempts")
+	}
+}
+
+func TestRetryConditions(t *testing.T) {
+	var server, data = newScriptedServer(t, scriptedResponse{status: http.StatusTooManyRequests, body: "0"})
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithRetry(RetryPolicy{BaseDelay: time.Millisecond, RetryOn: RetryOnServerError}))
+	if err := mixpanel.TrackEventOnly("Test Retry"); err == nil {
+		t.Error("Expected an error")
+	}
+	if len(data()) != 1 {
+		t.Error("Rate limits must not be retried, got", len(data()), "attempts")
+	}
+}
+
+func TestRetryWithoutPolicy(t *testing.T) {
+	var server, data = newScriptedServer(t, scriptedResponse{status: http.StatusServiceUnavailable, body: "0"})
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL))
+	if err := mixpanel.TrackEventOnly("Test Retry"); err == nil {
+		t.Error("Expected an error")
+	}
+	if len(data()) != 1 {
+		t.Error("Expected a single attempt, got", len(data()))
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	var policy = RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	var err = &APIError{StatusCode: http.StatusServiceUnavailable}
+	var expected = []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, delay := range expected {
+		if actual := policy.delay(err, i+1); actual != delay {
+			t.Error("Attempt", i+1, "expected", delay, "got", actual)
+		}
+	}
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if actual := policy.delay(err, 1); actual < 50*time.Millisecond || actual > 100*time.Millisecond {
+			t.Fatal("Jittered delay out of range", actual)
+		}
+	}
+	var rateLimited = &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 30 * time.Second}
+	if actual := policy.delay(rateLimited, 1); actual != 30*time.Second {
+		t.Error("Expected Retry-After to be honoured, got", actual)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if parseRetryAfter("7") != 7*time.Second {
+		t.Error("Unexpected delay for seconds")
+	}
+	var date = time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if delay := parseRetryAfter(date); delay <= 50*time.Second || delay > time.Minute {
+		t.Error("Unexpected delay for date", delay)
+	}
+	if parseRetryAfter("") != 0 || parseRetryAfter("soon") != 0 {
+		t.Error("Expected no delay for missing or invalid values")
+	}
+}
+
+func TestRetryProfileAddServerError(t *testing.T) {
+	var server, data = newScriptedServer(t,
+		scriptedResponse{status: http.StatusServiceUnavailable, body: "0"},
+		scriptedResponse{status: http.StatusOK, body: "1"},
+	)
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithRetry(RetryPolicy{BaseDelay: time.Millisecond}))
+	if err := mixpanel.ProfileAdd("User 0001", map[string]int64{"logins": 1}); err == nil {
+		t.Error("Expected the server error to be returned")
+	}
+	if sent := data(); len(sent) != 1 {
+		t.Error("Expected $add not to be retried, got", len(sent), "attempts")
+	}
+	if err := mixpanel.ProfileSet("User 0001", map[string]interface{}{"plan": "pro"}); err != nil {
+		t.Fatal(err)
+	}
+	if sent := data(); len(sent) != 2 {
+		t.Error("Expected $set to be sent once more, got", len(sent), "attempts")
+	}
+}
+
+func TestRetryProfileAppendNetworkError(t *testing.T) {
+	var calls = 0
+	var client = &http.Client{Transport: roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		calls++
+		return nil, errors.New("connection reset")
+	})}
+	var mixpanel = NewMixPanel("token", WithHTTPClient(client), WithRetry(RetryPolicy{BaseDelay: time.Millisecond}))
+	if err := mixpanel.ProfileAppend("User 0001", map[string]interface{}{"tags": "beta"}); err == nil {
+		t.Error("Expected the network error to be returned")
+	}
+	if calls != 1 {
+		t.Error("Expected $append not to be retried, got", calls, "attempts")
+	}
+	calls = 0
+	if err := mixpanel.ProfileUnion("User 0001", map[string]interface{}{"tags": []string{"beta"}}); err == nil {
+		t.Error("Expected the network error to be returned")
+	}
+	if calls != 3 {
+		t.Error("Expected $union to be retried, got", calls, "attempts")
+	}
+}
+
+func TestRetryProfileAddRateLimit(t *testing.T) {
+	var server, data = newScriptedServer(t,
+		scriptedResponse{status: http.StatusTooManyRequests, body: "0"},
+		scriptedResponse{status: http.StatusOK, body: "1"},
+	)
+	var mixpanel = NewMixPanel("token", WithBaseURL(server.URL), WithRetry(RetryPolicy{BaseDelay: time.Millisecond}))
+	if err := mixpanel.ProfileAdd("User 0001", map[string]int64{"logins": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if sent := data(); len(sent) != 2 {
+		t.Error("Expected a rate limited $add to be retried, got", len(sent), "attempts")
+	}
+}
+
+func TestRetryJitterClamped(t *testing.T) {
+	var cases = []struct {
+		jitter   float64
+		expected float64
+	}{
+		{-0.5, 0},
+		{0.5, 0.5},
+		{5, 1},
+	}
+	for _, c := range cases {
+		var mixpanel = NewMixPanel("token", WithRetry(RetryPolicy{BaseDelay: time.Second, Jitter: c.jitter}))
+		if mixpanel.retry.Jitter != c.expected {
+			t.Error("Expected jitter", c.jitter, "to become", c.expected, "got", mixpanel.retry.Jitter)
+		}
+		for attempt := 1; attempt <= 3; attempt++ {
+			if delay := mixpanel.retry.delay(errors.New("connection reset"), attempt); delay < 0 {
+				t.Error("Expected a delay of at least zero with jitter", c.jitter, "got", delay)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package mixpanel
+
+import (
+	"context"
+	"fmt"
+)
+
+// Scope is a lightweight child of a MixPanel bound to one user, for example for the duration of an HTTP request.
+// Events tracked through it carry its distinct id, ip address and properties, and profile updates target its user.
+type Scope struct {
+	m          *MixPanel
+	distinctID string
+	ipAddress  string
+	properties map[string]interface{}
+}
+
+// scopeKey is the context key for a Scope.
+type scopeKey struct{}
+
+// Scope creates a child client bound to distinctID, which may be empty for anonymous events. ipAddress may be empty, properties may be nil.
+func (m *MixPanel) Scope(distinctID string, ipAddress string, properties map[string]interface{}) *Scope {
+	return &Scope{m: m, distinctID: distinctID, ipAddress: ipAddress, properties: mergeMapsCopy(properties, nil)}
+}
+
+// With returns a child of the scope with extra properties, which take precedence over the scope's own.
+func (s *Scope) With(properties map[string]interface{}) *Scope {
+	return &Scope{m: s.m, distinctID: s.distinctID, ipAddress: s.ipAddress, properties: mergeMapsCopy(s.properties, properties)}
+}
+
+// DistinctID returns the user the scope is bound to.
+func (s *Scope) DistinctID() string {
+	return s.distinctID
+}
+
+// Track tracks the event with the scope's properties, distinct id and ip address added.
+// Properties already set on the event take precedence.
+func (s *Scope) Track(e *Event) error {
+	return s.TrackContext(context.Background(), e)
+}
+
+// TrackContext is like Track but uses ctx for the outgoing request.
+func (s *Scope) TrackContext(ctx context.Context, e *Event) error {
+	var scoped = &Event{Name: e.Name, Properties: mergeMapsCopy(s.properties, nil)}
+	if s.distinctID != "" {
+		scoped.DistinctID(s.distinctID)
+	}
+	if s.ipAddress != "" {
+		scoped.IP(s.ipAddress)
+	}
+	scoped.Props(e.Properties)
+	return s.m.TrackContext(ctx, scoped)
+}
+
+// Profile returns an empty ProfileUpdate for the scope's user, send it with UpdateProfile.
+func (s *Scope) Profile() *ProfileUpdate {
+	return NewProfileUpdate(s.distinctID)
+}
+
+// UpdateProfile sends the profile update through the scope's client.
+// The update must be for the scope's user, updates for anyone else are rejected.
+func (s *Scope) UpdateProfile(update *ProfileUpdate) error {
+	return s.UpdateProfileContext(context.Background(), update)
+}
+
+// UpdateProfileContext is like UpdateProfile but uses ctx for the outgoing request.
+func (s *Scope) UpdateProfileContext(ctx context.Context, update *ProfileUpdate) error {
+	if update.distinctID != s.distinctID {
+		return fmt.Errorf("Profile update for %q cannot be sent through the scope of %q", update.distinctID, s.distinctID)
+	}
+	return s.m.UpdateProfileContext(ctx, update)
+}
+
+// ContextWithScope returns a copy of ctx carrying the scope.
+func ContextWithScope(ctx context.Context, scope *Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// ScopeFromContext returns the scope stored in ctx by ContextWithScope.
+func ScopeFromContext(ctx context.Context) (*Scope, bool) {
+	scope, ok := ctx.Value(scopeKey{}).(*Scope)
+	return scope, ok
+}
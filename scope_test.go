@@ -0,0 +1,65 @@
+package mixpanel
+
+import (
+	"context"
+	"testing"
+)
+
+func TestScopeTrack(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	var scope = mixpanel.Scope("User 0001", "64.2.4.1", map[string]interface{}{"route": "/signup", "plan": "free"})
+	var child = scope.With(map[string]interface{}{"step": 2})
+	if err := child.Track(NewEvent("Signup").Prop("plan", "pro")); err != nil {
+		t.Fatal(err)
+	}
+	var properties = consumer.payloads[0]["properties"].(map[string]interface{})
+	var expected = map[string]interface{}{"distinct_id": "User 0001", "ip": "64.2.4.1", "route": "/signup", "plan": "pro", "step": 2}
+	for key, value := range expected {
+		if properties[key] != value {
+			t.Error("Property", key, "expected", value, "got", properties[key])
+		}
+	}
+	if err := scope.Track(NewEvent("Visit")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := consumer.payloads[1]["properties"].(map[string]interface{})["step"]; ok {
+		t.Error("Child properties must not leak into the parent scope")
+	}
+}
+
+func TestScopeProfile(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	var scope = mixpanel.Scope("User 0001", "", nil)
+	if err := scope.UpdateProfile(scope.Profile().Set("$name", "One")); err != nil {
+		t.Fatal(err)
+	}
+	if consumer.payloads[0]["$distinct_id"] != "User 0001" {
+		t.Error("Unexpected record", consumer.payloads[0])
+	}
+}
+
+func TestScopeProfileOtherUser(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	var scope = mixpanel.Scope("User 0001", "", nil)
+	if err := scope.UpdateProfile(NewProfileUpdate("User 0002").Set("$name", "Two")); err == nil {
+		t.Error("Expected an error for a profile update of another user")
+	}
+	if len(consumer.payloads) != 0 {
+		t.Error("Expected the update not to be sent", consumer.payloads)
+	}
+}
+
+func TestScopeContext(t *testing.T) {
+	var mixpanel = NewMixPanel("token")
+	if _, ok := ScopeFromContext(context.Background()); ok {
+		t.Error("Expected no scope in an empty context")
+	}
+	var scope = mixpanel.Scope("User 0001", "", nil)
+	var ctx = ContextWithScope(context.Background(), scope)
+	if found, ok := ScopeFromContext(ctx); !ok || found != scope || found.DistinctID() != "User 0001" {
+		t.Error("Expected the stored scope", found)
+	}
+}
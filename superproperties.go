@@ -0,0 +1,41 @@
+package mixpanel
+
+// Register adds super properties which are merged into every event the client tracks or imports.
+// Properties given to an event take precedence over super properties. It is safe for concurrent use.
+func (m *MixPanel) Register(properties map[string]interface{}) {
+	m.superMutex.Lock()
+	defer m.superMutex.Unlock()
+	m.superProperties = mergeMapsCopy(m.superProperties, properties)
+}
+
+// RegisterOnce is like Register but keeps the value of super properties which are already registered.
+func (m *MixPanel) RegisterOnce(properties map[string]interface{}) {
+	m.superMutex.Lock()
+	defer m.superMutex.Unlock()
+	m.superProperties = mergeMapsCopy(properties, m.superProperties)
+}
+
+// Unregister removes the super properties with the given names.
+func (m *MixPanel) Unregister(names ...string) {
+	m.superMutex.Lock()
+	defer m.superMutex.Unlock()
+	var properties = mergeMapsCopy(m.superProperties, nil)
+	for _, name := range names {
+		delete(properties, name)
+	}
+	m.superProperties = properties
+}
+
+// SuperProperties returns a copy of the registered super properties.
+func (m *MixPanel) SuperProperties() map[string]interface{} {
+	m.superMutex.RLock()
+	defer m.superMutex.RUnlock()
+	return mergeMapsCopy(m.superProperties, nil)
+}
+
+// withSuperProperties returns a copy of properties with the super properties merged underneath.
+func (m *MixPanel) withSuperProperties(properties map[string]interface{}) map[string]interface{} {
+	m.superMutex.RLock()
+	defer m.superMutex.RUnlock()
+	return mergeMapsCopy(m.superProperties, properties)
+}
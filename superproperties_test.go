@@ -0,0 +1,57 @@
+package mixpanel
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSuperProperties(t *testing.T) {
+	var consumer = &recordingConsumer{}
+	var mixpanel = NewMixPanel("token", WithConsumer(consumer))
+	mixpanel.Register(map[string]interface{}{"service": "api", "env": "prod", "token": "ignored"})
+	mixpanel.RegisterOnce(map[string]interface{}{"env": "dev", "region": "eu"})
+	mixpanel.Register(map[string]interface{}{"build_sha": "abc"})
+	mixpanel.Unregister("build_sha")
+	if err := mixpanel.TrackEventWithParameters("Test SuperProperties", map[string]interface{}{"service": "worker"}); err != nil {
+		t.Fatal(err)
+	}
+	var properties = consumer.payloads[0]["properties"].(map[string]interface{})
+	var expected = map[string]interface{}{"service": "worker", "env": "prod", "region": "eu", "token": "token"}
+	for key, value := range expected {
+		if properties[key] != value {
+			t.Error("Property", key, "expected", value, "got", properties[key])
+		}
+	}
+	if _, ok := properties["build_sha"]; ok {
+		t.Error("Unregistered property was sent")
+	}
+}
+
+func TestSuperPropertiesCopy(t *testing.T) {
+	var mixpanel = NewMixPanel("token")
+	mixpanel.Register(map[string]interface{}{"env": "prod"})
+	mixpanel.SuperProperties()["env"] = "dev"
+	if mixpanel.SuperProperties()["env"] != "prod" {
+		t.Error("SuperProperties must return a copy")
+	}
+}
+
+func TestSuperPropertiesConcurrent(t *testing.T) {
+	var mixpanel = NewMixPanel("token", WithConsumer(NoopConsumer{}))
+	var group sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		group.Add(1)
+		go func(i int) {
+			defer group.Done()
+			var key = "key" + strconv.Itoa(i)
+			mixpanel.Register(map[string]interface{}{key: i})
+			mixpanel.TrackEventOnly("Test SuperProperties")
+			mixpanel.Unregister(key)
+		}(i)
+	}
+	group.Wait()
+	if len(mixpanel.SuperProperties()) != 0 {
+		t.Error("Expected no super properties left", mixpanel.SuperProperties())
+	}
+}
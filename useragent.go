@@ -0,0 +1,169 @@
+package mixpanel
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Enricher adds properties to an event, apply it with Event.Enrich.
+type Enricher func(e *Event)
+
+// Enrich applies the enrichers to the event in order.
+func (e *Event) Enrich(enrichers ...Enricher) *Event {
+	for _, enricher := range enrichers {
+		enricher(e)
+	}
+	return e
+}
+
+// UserAgent returns an Enricher which sets the $browser, $browser_version, $os and $device properties
+// parsed from a User-Agent header, the same way the mixpanel JavaScript library does.
+func UserAgent(userAgent string) Enricher {
+	return func(e *Event) {
+		e.Props(UserAgentProperties(userAgent))
+	}
+}
+
+// UserAgentProperties parses a User-Agent header into mixpanel's $browser, $browser_version, $os and $device
+// properties. Properties which cannot be determined are left out.
+func UserAgentProperties(userAgent string) map[string]interface{} {
+	var properties = make(map[string]interface{})
+	if browser := parseBrowser(userAgent); browser != "" {
+		properties["$browser"] = browser
+		if version, ok := parseBrowserVersion(userAgent, browser); ok {
+			properties["$browser_version"] = version
+		}
+	}
+	if os := parseOS(userAgent); os != "" {
+		properties["$os"] = os
+	}
+	if device := parseDevice(userAgent); device != "" {
+		properties["$device"] = device
+	}
+	return properties
+}
+
+var blackBerryPattern = regexp.MustCompile(`(?i)(BlackBerry|PlayBook|BB10)`)
+
+// parseBrowser returns the browser name, the order of the checks matters as many browsers claim to be others.
+func parseBrowser(userAgent string) string {
+	switch {
+	case strings.Contains(userAgent, " OPR/"):
+		if strings.Contains(userAgent, "Mini") {
+			return "Opera Mini"
+		}
+		return "Opera"
+	case blackBerryPattern.MatchString(userAgent):
+		return "BlackBerry"
+	case strings.Contains(userAgent, "IEMobile") || strings.Contains(userAgent, "WPDesktop"):
+		return "Internet Explorer Mobile"
+	case strings.Contains(userAgent, "SamsungBrowser/"):
+		return "Samsung Internet"
+	case strings.Contains(userAgent, "Edge") || strings.Contains(userAgent, "Edg/"):
+		return "Microsoft Edge"
+	case strings.Contains(userAgent, "FBIOS"):
+		return "Facebook Mobile"
+	case strings.Contains(userAgent, "Chrome"):
+		return "Chrome"
+	case strings.Contains(userAgent, "CriOS"):
+		return "Chrome iOS"
+	case strings.Contains(userAgent, "UCWEB") || strings.Contains(userAgent, "UCBrowser"):
+		return "UC Browser"
+	case strings.Contains(userAgent, "FxiOS"):
+		return "Firefox iOS"
+	case strings.Contains(userAgent, "Safari"):
+		if strings.Contains(userAgent, "Mobile") {
+			return "Mobile Safari"
+		}
+		return "Safari"
+	case strings.Contains(userAgent, "Android"):
+		return "Android Mobile"
+	case strings.Contains(userAgent, "Konqueror"):
+		return "Konqueror"
+	case strings.Contains(userAgent, "Firefox"):
+		return "Firefox"
+	case strings.Contains(userAgent, "MSIE") || strings.Contains(userAgent, "Trident/"):
+		return "Internet Explorer"
+	case strings.Contains(userAgent, "Gecko"):
+		return "Mozilla"
+	}
+	return ""
+}
+
+// browserVersionPatterns finds the version of each browser, the version is the last submatch.
+var browserVersionPatterns = map[string]*regexp.Regexp{
+	"Internet Explorer Mobile": regexp.MustCompile(`rv:(\d+(?:\.\d+)?)`),
+	"Microsoft Edge":           regexp.MustCompile(`Edge?/(\d+(?:\.\d+)?)`),
+	"Chrome":                   regexp.MustCompile(`Chrome/(\d+(?:\.\d+)?)`),
+	"Chrome iOS":               regexp.MustCompile(`CriOS/(\d+(?:\.\d+)?)`),
+	"UC Browser":               regexp.MustCompile(`(?:UCBrowser|UCWEB)/(\d+(?:\.\d+)?)`),
+	"Safari":                   regexp.MustCompile(`Version/(\d+(?:\.\d+)?)`),
+	"Mobile Safari":            regexp.MustCompile(`Version/(\d+(?:\.\d+)?)`),
+	"Opera":                    regexp.MustCompile(`(?:Opera|OPR)/(\d+(?:\.\d+)?)`),
+	"Firefox":                  regexp.MustCompile(`Firefox/(\d+(?:\.\d+)?)`),
+	"Firefox iOS":              regexp.MustCompile(`FxiOS/(\d+(?:\.\d+)?)`),
+	"Konqueror":                regexp.MustCompile(`Konqueror[:/](\d+(?:\.\d+)?)`),
+	"BlackBerry":               regexp.MustCompile(`(?:BlackBerry |Version/)(\d+(?:\.\d+)?)`),
+	"Android Mobile":           regexp.MustCompile(`(?i)android\s(\d+(?:\.\d+)?)`),
+	"Samsung Internet":         regexp.MustCompile(`SamsungBrowser/(\d+(?:\.\d+)?)`),
+	"Internet Explorer":        regexp.MustCompile(`(?:rv:|MSIE )(\d+(?:\.\d+)?)`),
+	"Mozilla":                  regexp.MustCompile(`rv:(\d+(?:\.\d+)?)`),
+}
+
+// parseBrowserVersion returns the major and minor version of the browser as a number.
+func parseBrowserVersion(userAgent string, browser string) (float64, bool) {
+	var pattern, ok = browserVersionPatterns[browser]
+	if !ok {
+		return 0, false
+	}
+	var match = pattern.FindStringSubmatch(userAgent)
+	if match == nil {
+		return 0, false
+	}
+	version, err := strconv.ParseFloat(match[len(match)-1], 64)
+	return version, err == nil
+}
+
+// parseOS returns the operating system name.
+func parseOS(userAgent string) string {
+	switch {
+	case strings.Contains(strings.ToLower(userAgent), "windows"):
+		if strings.Contains(userAgent, "Phone") {
+			return "Windows Phone"
+		}
+		return "Windows"
+	case strings.Contains(userAgent, "iPhone") || strings.Contains(userAgent, "iPad") || strings.Contains(userAgent, "iPod"):
+		return "iOS"
+	case strings.Contains(userAgent, "Android"):
+		return "Android"
+	case blackBerryPattern.MatchString(userAgent):
+		return "BlackBerry"
+	case strings.Contains(strings.ToLower(userAgent), "mac"):
+		return "Mac OS X"
+	case strings.Contains(userAgent, "Linux"):
+		return "Linux"
+	case strings.Contains(userAgent, "CrOS"):
+		return "Chrome OS"
+	}
+	return ""
+}
+
+// parseDevice returns the device name for mobile devices.
+func parseDevice(userAgent string) string {
+	switch {
+	case strings.Contains(strings.ToLower(userAgent), "windows phone") || strings.Contains(userAgent, "WPDesktop"):
+		return "Windows Phone"
+	case strings.Contains(userAgent, "iPad"):
+		return "iPad"
+	case strings.Contains(userAgent, "iPod"):
+		return "iPod Touch"
+	case strings.Contains(userAgent, "iPhone"):
+		return "iPhone"
+	case blackBerryPattern.MatchString(userAgent):
+		return "BlackBerry"
+	case strings.Contains(userAgent, "Android"):
+		return "Android"
+	}
+	return ""
+}
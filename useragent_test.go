@@ -0,0 +1,64 @@
+package mixpanel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUserAgentProperties(t *testing.T) {
+	var tests = []struct {
+		userAgent string
+		expected  map[string]interface{}
+	}{
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			map[string]interface{}{"$browser": "Chrome", "$browser_version": 120.0, "$os": "Windows"},
+		},
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91",
+			map[string]interface{}{"$browser": "Microsoft Edge", "$browser_version": 120.0, "$os": "Windows"},
+		},
+		{
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+			map[string]interface{}{"$browser": "Safari", "$browser_version": 17.2, "$os": "Mac OS X"},
+		},
+		{
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1",
+			map[string]interface{}{"$browser": "Mobile Safari", "$browser_version": 17.2, "$os": "iOS", "$device": "iPhone"},
+		},
+		{
+			"Mozilla/5.0 (iPad; CPU OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1",
+			map[string]interface{}{"$browser": "Chrome iOS", "$browser_version": 120.0, "$os": "iOS", "$device": "iPad"},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Mobile Safari/537.36",
+			map[string]interface{}{"$browser": "Samsung Internet", "$browser_version": 23.0, "$os": "Android", "$device": "Android"},
+		},
+		{
+			"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			map[string]interface{}{"$browser": "Firefox", "$browser_version": 121.0, "$os": "Linux"},
+		},
+		{
+			"Mozilla/5.0 (Windows NT 6.1; WOW64; Trident/7.0; rv:11.0) like Gecko",
+			map[string]interface{}{"$browser": "Internet Explorer", "$browser_version": 11.0, "$os": "Windows"},
+		},
+		{
+			"curl/8.4.0",
+			map[string]interface{}{},
+		},
+	}
+	for _, test := range tests {
+		if properties := UserAgentProperties(test.userAgent); !reflect.DeepEqual(properties, test.expected) {
+			t.Errorf("Expected %v for %q, got %v", test.expected, test.userAgent, properties)
+		}
+	}
+}
+
+func TestEventEnrichUserAgent(t *testing.T) {
+	var event = NewEvent("Page View").
+		Prop("$browser", "Unknown").
+		Enrich(UserAgent("Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0"))
+	if event.Properties["$browser"] != "Firefox" || event.Properties["$os"] != "Linux" {
+		t.Error("Expected the user agent properties to be set, got", event.Properties)
+	}
+}